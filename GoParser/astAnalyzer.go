@@ -2,13 +2,21 @@ package main
 
 import (
 	"GoParser/model"
+	"GoParser/utils"
+	"errors"
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
+	"path"
+	"sort"
+	"strings"
 )
 
 type ASTAnalyzer interface {
-	AnalyzeFile(src string) (model.GenericCounters, error)
+	AnalyzeFile(src string) (model.AnalysisResult, error)
+	AnalyzeFiles(files []model.SourceFile) (model.AnalysisResult, error)
+	AnalyzeTree(tree utils.SourceTree) (model.AnalysisResult, error)
 }
 
 type astAnalyzerImpl struct{}
@@ -17,23 +25,280 @@ func NewASTAnalyzer() ASTAnalyzer {
 	return &astAnalyzerImpl{}
 }
 
-func (a *astAnalyzerImpl) AnalyzeFile(src string) (model.GenericCounters, error) {
+func (a *astAnalyzerImpl) AnalyzeFile(src string) (model.AnalysisResult, error) {
 	fset := token.NewFileSet()
 	file, err := parser.ParseFile(fset, "", src, parser.AllErrors)
 	if err != nil {
-		return model.GenericCounters{}, err
+		return model.AnalysisResult{}, err
 	}
 
-	// First pass: collect type bounds information (for Erweiterung 2 & 3)
-	typeBoundsInfo := collectTypeBoundsInfo(file)
+	files := []*ast.File{file}
+	return analyzePackage(fset, files, []string{utils.FileCategory("", src)}, collectPackageInfo(files))
+}
 
-	// Second pass: analyze file with information about type bounds available
-	counters, err := analyzeASTAndGetCounters(file, typeBoundsInfo)
-	if err != nil {
-		return model.GenericCounters{}, err
+// AnalyzeFiles analyzes files that are already loaded, see AnalyzeTree
+func (a *astAnalyzerImpl) AnalyzeFiles(files []model.SourceFile) (model.AnalysisResult, error) {
+	return a.AnalyzeTree(utils.NewMemoryTree(files))
+}
+
+// AnalyzeTree groups the files of the tree into packages (same directory and same
+// package clause) and analyzes every package as a whole, so type bounds declared
+// in one file are known when methods in another file of the package are counted.
+// Files that cannot be parsed are skipped; their errors are returned joined
+// together with the result of all remaining files. The counters of every package
+// are also attributed to the module (go.mod) it belongs to.
+func (a *astAnalyzerImpl) AnalyzeTree(tree utils.SourceTree) (model.AnalysisResult, error) {
+	return analyzeTree(tree, func(fset *token.FileSet, pkg *parsedPackage) (model.AnalysisResult, error) {
+		return analyzePackage(fset, pkg.files, pkg.categories, collectPackageInfo(pkg.files))
+	})
+}
+
+// analyzeTree streams the files of a source tree. The files of a directory are adjacent
+// (see utils.SortSourcePaths), so as soon as the next directory starts, the packages of the
+// previous one are parsed, handed to analyze and dropped again. Only the results are kept;
+// sources and syntax trees of at most one directory are in memory at any time.
+func analyzeTree(tree utils.SourceTree, analyze func(fset *token.FileSet, pkg *parsedPackage) (model.AnalysisResult, error)) (model.AnalysisResult, error) {
+	result := model.AnalysisResult{}
+	modules := make(moduleRoots)
+	var workspace *model.GoDirectives
+	var errs []error
+
+	dir := ""
+	var batch []model.SourceFile
+	flush := func() {
+		// Every directory gets its own FileSet, so the position tables are released with the syntax trees
+		fset := token.NewFileSet()
+		packages, err := parsePackages(fset, batch)
+		errs = append(errs, err)
+		for _, pkg := range packages {
+			pkgResult, err := analyze(fset, pkg)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			addModuleCounters(&pkgResult, modules.moduleOf(pkg.dir), pkgResult.Counters)
+			aggregateResult(&result, pkgResult)
+		}
+		batch = nil
 	}
 
-	return counters, nil
+	for f, err := range utils.SourceFiles(tree) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if fileDir := path.Dir(f.Path); fileDir != dir {
+			flush()
+			dir = fileDir
+		}
+		switch {
+		case path.Base(f.Path) == "go.mod":
+			modules.add(f)
+		case f.Path == "go.work":
+			directives := goDirectives(f)
+			workspace = &directives
+		}
+		batch = append(batch, f)
+	}
+	flush()
+
+	result.Go = repositoryGoDirectives(workspace, modules)
+	return result, errors.Join(errs...)
+}
+
+// parsedPackage contains all parsed files of one directory sharing the same package clause.
+// categories holds the file category (model.FileCategory*) of every file.
+type parsedPackage struct {
+	dir        string
+	name       string
+	files      []*ast.File
+	filePaths  []string
+	categories []string
+}
+
+// parsePackages parses all .go files and groups them by directory and package name.
+// Other files (e.g. go.mod) are ignored. The returned packages are sorted by
+// directory and name to keep the analysis deterministic.
+func parsePackages(fset *token.FileSet, files []model.SourceFile) ([]*parsedPackage, error) {
+	var errs []error
+	byKey := make(map[string]*parsedPackage)
+
+	for _, f := range files {
+		if !strings.HasSuffix(f.Path, ".go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, f.Path, f.Content, parser.AllErrors)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		dir := path.Dir(f.Path)
+		key := dir + ":" + file.Name.Name
+		pkg, ok := byKey[key]
+		if !ok {
+			pkg = &parsedPackage{dir: dir, name: file.Name.Name}
+			byKey[key] = pkg
+		}
+		pkg.files = append(pkg.files, file)
+		pkg.filePaths = append(pkg.filePaths, f.Path)
+		category := f.Category
+		if category == "" {
+			category = utils.FileCategory(f.Path, f.Content)
+		}
+		pkg.categories = append(pkg.categories, category)
+	}
+
+	keys := make([]string, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	packages := make([]*parsedPackage, 0, len(keys))
+	for _, key := range keys {
+		packages = append(packages, byKey[key])
+	}
+
+	return packages, errors.Join(errs...)
+}
+
+// packageInfo bundles everything the first pass learns about a package.
+// The go/types fields are only set in the semantic analysis mode.
+type packageInfo struct {
+	typeSpecs      map[string]*ast.TypeSpec
+	typeBoundsInfo map[string]TypeBoundInfo
+	genericFuncs   map[string]bool
+	iterators      iteratorFuncs
+	typesPkg       *types.Package
+	typesInfo      *types.Info
+}
+
+// collectPackageInfo runs the first pass over all files of a package
+func collectPackageInfo(files []*ast.File) *packageInfo {
+	typeSpecs := collectTypeSpecs(files)
+	return &packageInfo{
+		typeSpecs:      typeSpecs,
+		typeBoundsInfo: collectTypeBoundsInfo(files, typeSpecs),
+		genericFuncs:   collectGenericFuncs(files),
+		iterators:      collectIteratorFuncs(files),
+	}
+}
+
+// The counters of every file are also added to the counters of its category.
+func analyzePackage(fset *token.FileSet, files []*ast.File, categories []string, pkgInfo *packageInfo) (model.AnalysisResult, error) {
+	// Second pass: analyze every file with information about the package available
+	result := model.AnalysisResult{}
+	candidates := newCandidateCollector(fset, pkgInfo)
+	fileCategories := make(map[string]string)
+	for i, file := range files {
+		fileCategories[fset.Position(file.Pos()).Filename] = categories[i]
+		candidates.addFile(file)
+		fileCounters, err := analyzeASTAndGetCounters(file, pkgInfo)
+		if err != nil {
+			return model.AnalysisResult{}, err
+		}
+		declarations := collectDeclarations(fset, file, pkgInfo)
+		countGenericAPIUsage(file, &result.GenericAPIs, &fileCounters)
+		for _, declaration := range declarations {
+			countConstraintKinds(&fileCounters, declaration)
+			countTypeParamStats(&result.TypeParams, declaration)
+		}
+		addCounters(&result, categories[i], fileCounters)
+		result.Declarations = append(result.Declarations, declarations...)
+	}
+
+	// Familien und sort.Interface-Typen können sich über mehrere Dateien erstrecken,
+	// sie werden der Kategorie der Datei ihrer Fundstelle zugeordnet
+	result.Candidates = candidates.result()
+	families := make(map[string]bool)
+	for _, candidate := range result.Candidates {
+		candidateCounters := model.GenericCounters{}
+		countCandidate(&candidateCounters, candidate, families)
+		addCounters(&result, fileCategories[candidate.File], candidateCounters)
+	}
+
+	return result, nil
+}
+
+// addCounters adds counters to the total and to the counters of the category
+func addCounters(result *model.AnalysisResult, category string, counters model.GenericCounters) {
+	aggregateCounters(&result.Counters, counters)
+	if result.ByCategory == nil {
+		result.ByCategory = make(map[string]model.GenericCounters)
+	}
+	categoryCounters := result.ByCategory[category]
+	aggregateCounters(&categoryCounters, counters)
+	result.ByCategory[category] = categoryCounters
+}
+
+// typeParamUsages determines for every type parameter where it is used: in the parameters,
+// the results or the body of a function (the definition of a type counts as body) and in the
+// constraints of the other type parameters. Uses are matched by name.
+func typeParamUsages(typeParams, params, results *ast.FieldList, body ast.Node) []string {
+	var usages []string
+	for i, field := range typeParams.List {
+		for _, name := range field.Names {
+			var locations []string
+			if params != nil && mentionsIdent(params, name.Name) {
+				locations = append(locations, model.TypeParamUsageParams)
+			}
+			if results != nil && mentionsIdent(results, name.Name) {
+				locations = append(locations, model.TypeParamUsageResults)
+			}
+			if body != nil && mentionsIdent(body, name.Name) {
+				locations = append(locations, model.TypeParamUsageBody)
+			}
+			// Im eigenen Constraint ist die Verwendung selbstreferenziell und zählt nicht
+			for j, other := range typeParams.List {
+				if j != i && mentionsIdent(other.Type, name.Name) {
+					locations = append(locations, model.TypeParamUsageConstraint)
+					break
+				}
+			}
+			if len(locations) == 0 {
+				locations = append(locations, model.TypeParamUsageUnused)
+			}
+			usages = append(usages, strings.Join(locations, ","))
+		}
+	}
+	return usages
+}
+
+// countTypeParamStats adds the arity and the type parameter usages of a declaration to the histograms
+func countTypeParamStats(stats *model.TypeParamStats, declaration model.GenericDeclaration) {
+	if stats.Arity == nil {
+		stats.Arity = make(map[string]int)
+		stats.Usage = make(map[string]int)
+	}
+	switch len(declaration.TypeParams) {
+	case 1:
+		stats.Arity[model.TypeParamArity1]++
+	case 2:
+		stats.Arity[model.TypeParamArity2]++
+	case 3:
+		stats.Arity[model.TypeParamArity3]++
+	default:
+		stats.Arity[model.TypeParamArity4Plus]++
+	}
+	for _, usage := range declaration.TypeParamUsages {
+		for _, location := range strings.Split(usage, ",") {
+			stats.Usage[location]++
+		}
+	}
+}
+
+// aggregateTypeParamStats adds the histograms of source to target
+func aggregateTypeParamStats(target *model.TypeParamStats, source model.TypeParamStats) {
+	if target.Arity == nil {
+		target.Arity = make(map[string]int)
+		target.Usage = make(map[string]int)
+	}
+	for arity, count := range source.Arity {
+		target.Arity[arity] += count
+	}
+	for usage, count := range source.Usage {
+		target.Usage[usage] += count
+	}
 }
 
 // TypeBoundInfo stores information about a type's bounds
@@ -42,61 +307,191 @@ type TypeBoundInfo struct {
 	hasStructBound     bool // Erweiterung 2: tracks if any bound is a struct
 }
 
-func collectTypeBoundsInfo(file *ast.File) map[string]TypeBoundInfo {
+// collectGenericFuncs returns the names of all package level generic functions
+func collectGenericFuncs(files []*ast.File) map[string]bool {
+	genericFuncs := make(map[string]bool)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Recv == nil && funcDecl.Type.TypeParams != nil && len(funcDecl.Type.TypeParams.List) > 0 {
+				genericFuncs[funcDecl.Name.Name] = true
+			}
+		}
+	}
+	return genericFuncs
+}
+
+// collectTypeSpecs returns all package level type declarations of the given files by name.
+// It is used to resolve constraints that are declared in another file of the same package,
+// where ident.Obj is not set by the parser.
+func collectTypeSpecs(files []*ast.File) map[string]*ast.TypeSpec {
+	typeSpecs := make(map[string]*ast.TypeSpec)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+					typeSpecs[typeSpec.Name.Name] = typeSpec
+				}
+			}
+		}
+	}
+	return typeSpecs
+}
+
+// resolveTypeSpec looks up the declaration of ident, first within its file and then within the package
+func resolveTypeSpec(ident *ast.Ident, typeSpecs map[string]*ast.TypeSpec) *ast.TypeSpec {
+	if ident.Obj != nil {
+		if ts, ok := ident.Obj.Decl.(*ast.TypeSpec); ok {
+			return ts
+		}
+		return nil
+	}
+	return typeSpecs[ident.Name]
+}
+
+func collectTypeBoundsInfo(files []*ast.File, typeSpecs map[string]*ast.TypeSpec) map[string]TypeBoundInfo {
 	typeBoundsInfo := make(map[string]TypeBoundInfo)
 
-	ast.Inspect(file, func(n ast.Node) bool {
-		if typeSpec, ok := n.(*ast.TypeSpec); ok {
-			if typeSpec.TypeParams != nil && len(typeSpec.TypeParams.List) > 0 {
-				info := TypeBoundInfo{}
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			if typeSpec, ok := n.(*ast.TypeSpec); ok {
+				if typeSpec.TypeParams != nil && len(typeSpec.TypeParams.List) > 0 {
+					typeBoundsInfo[typeSpec.Name.Name] = classifyTypeParamList(typeSpec.TypeParams, typeSpecs)
+				}
+			}
+			return true
+		})
+	}
 
-				for _, tp := range typeSpec.TypeParams.List {
-					if tp.Type != nil {
-						isTrivial := false
+	return typeBoundsInfo
+}
 
-						// Check for "any"
-						if ident, ok := tp.Type.(*ast.Ident); ok && ident.Name == "any" {
-							isTrivial = true
-						}
+// classifyTypeParamList classifies the constraints of a type parameter list syntactically
+func classifyTypeParamList(typeParams *ast.FieldList, typeSpecs map[string]*ast.TypeSpec) TypeBoundInfo {
+	info := TypeBoundInfo{}
+	for _, tp := range typeParams.List {
+		if tp.Type != nil {
+			isTrivial, isStruct := classifyConstraint(tp.Type, typeSpecs)
+			if !isTrivial {
+				info.hasNonTrivialBound = true
+			}
+			if isStruct {
+				info.hasStructBound = true
+			}
+		}
+	}
+	return info
+}
 
-						// Check for empty interface{}
-						if iface, ok := tp.Type.(*ast.InterfaceType); ok && iface.Methods != nil && iface.Methods.NumFields() == 0 {
-							isTrivial = true
-						}
+// funcTypeBoundInfo classifies the type parameters of a generic function. go/types is
+// preferred if available, unresolved constraints fall back to the syntactic classification.
+func funcTypeBoundInfo(funcDecl *ast.FuncDecl, pkgInfo *packageInfo) TypeBoundInfo {
+	if pkgInfo.typesInfo != nil {
+		if fn, ok := pkgInfo.typesInfo.Defs[funcDecl.Name].(*types.Func); ok {
+			if info, ok := classifyTypeParams(fn.Type().(*types.Signature).TypeParams()); ok {
+				return info
+			}
+		}
+	}
+	return classifyTypeParamList(funcDecl.Type.TypeParams, pkgInfo.typeSpecs)
+}
 
-						// Check if constraint is an empty interface or struct defined elsewhere
-						if ident, ok := tp.Type.(*ast.Ident); ok {
-							obj := ident.Obj
-							if obj != nil {
-								if ts, ok := obj.Decl.(*ast.TypeSpec); ok {
-									// Erweiterung 1: Check if constraint is an empty interface
-									if iface, ok := ts.Type.(*ast.InterfaceType); ok && iface.Methods != nil && iface.Methods.NumFields() == 0 {
-										isTrivial = true
-									}
-									// Erweiterung 2: Check if constraint is a struct type
-									if _, ok := ts.Type.(*ast.StructType); ok {
-										info.hasStructBound = true
-									}
-								}
-							}
-						}
+// hasInlineTypeSet reports whether a type parameter of the list is constrained by inline type terms
+func hasInlineTypeSet(typeParams *ast.FieldList, analyzer *typeSetAnalyzer) bool {
+	for _, field := range typeParams.List {
+		if info, ok := analyzer.analyzeConstraint(field.Type); ok && info.isTypeSet() {
+			return true
+		}
+	}
+	return false
+}
 
-						if !isTrivial {
-							info.hasNonTrivialBound = true
-						}
-					}
+// classifyConstraint reports whether a constraint is trivial and whether it is a struct type
+func classifyConstraint(constraint ast.Expr, typeSpecs map[string]*ast.TypeSpec) (isTrivial, isStruct bool) {
+	// Check for "any"
+	if ident, ok := constraint.(*ast.Ident); ok && ident.Name == "any" {
+		isTrivial = true
+	}
+
+	// Check for empty interface{}
+	if iface, ok := constraint.(*ast.InterfaceType); ok && iface.Methods != nil && iface.Methods.NumFields() == 0 {
+		isTrivial = true
+	}
+
+	// Check if constraint is an empty interface or struct defined elsewhere in the package
+	if ident, ok := constraint.(*ast.Ident); ok {
+		if ts := resolveTypeSpec(ident, typeSpecs); ts != nil {
+			// Erweiterung 1: Check if constraint is an empty interface
+			if iface, ok := ts.Type.(*ast.InterfaceType); ok && iface.Methods != nil && iface.Methods.NumFields() == 0 {
+				isTrivial = true
+			}
+			// Erweiterung 2: Check if constraint is a struct type
+			if _, ok := ts.Type.(*ast.StructType); ok {
+				isStruct = true
+			}
+		}
+	}
+
+	return isTrivial, isStruct
+}
+
+// countGenericTypeShape counts a generic type definition by the shape of its underlying type
+func countGenericTypeShape(typeSpec *ast.TypeSpec, counters *model.GenericCounters) {
+	switch t := typeSpec.Type.(type) {
+	case *ast.InterfaceType:
+		counters.InterfaceGeneric++
+		if interfaceMethodUsesTypeParam(t, typeSpec.TypeParams) {
+			counters.InterfaceGenericMethodUsesTypeParam++
+		}
+	case *ast.FuncType:
+		counters.FuncTypeGeneric++
+	case *ast.MapType:
+		counters.MapTypeGeneric++
+	case *ast.ArrayType:
+		counters.SliceTypeGeneric++
+	case *ast.ChanType:
+		counters.ChanTypeGeneric++
+	}
+}
+
+// interfaceMethodUsesTypeParam reports whether a method signature of the interface mentions one of the type parameters
+func interfaceMethodUsesTypeParam(iface *ast.InterfaceType, typeParams *ast.FieldList) bool {
+	if iface.Methods == nil {
+		return false
+	}
+	for _, method := range iface.Methods.List {
+		if len(method.Names) == 0 {
+			continue // eingebettetes Interface oder Typ-Term
+		}
+		for _, field := range typeParams.List {
+			for _, name := range field.Names {
+				if mentionsIdent(method.Type, name.Name) {
+					return true
 				}
-				typeBoundsInfo[typeSpec.Name.Name] = info
 			}
 		}
-		return true
-	})
+	}
+	return false
+}
 
-	return typeBoundsInfo
+// isInstantiatedType reports whether a type expression instantiates a generic type, e.g. List[int] or pkg.Map[K, V].
+// On the right-hand side of a type declaration an index expression can only be an instantiation.
+func isInstantiatedType(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return isInstantiatedType(e.X)
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		return true
+	}
+	return false
 }
 
-func analyzeASTAndGetCounters(file *ast.File, typeBoundsInfo map[string]TypeBoundInfo) (model.GenericCounters, error) {
+func analyzeASTAndGetCounters(file *ast.File, pkgInfo *packageInfo) (model.GenericCounters, error) {
 	counters := model.GenericCounters{}
+	typeSets := newTypeSetAnalyzer(importPaths(file), pkgInfo)
 
 	ast.Inspect(file, func(n ast.Node) bool {
 		switch node := n.(type) {
@@ -108,6 +503,21 @@ func analyzeASTAndGetCounters(file *ast.File, typeBoundsInfo map[string]TypeBoun
 				counters.FuncTotal++
 				if node.Type.TypeParams != nil && len(node.Type.TypeParams.List) > 0 {
 					counters.FuncGeneric++
+					countInlineTypeSets(node.Type.TypeParams, typeSets, &counters)
+
+					// Type Bounds wie bei generischen Structs klassifizieren
+					boundInfo := funcTypeBoundInfo(node, pkgInfo)
+					if boundInfo.hasNonTrivialBound {
+						counters.FuncGenericNonTrivialBound++
+					} else {
+						counters.FuncGenericTrivialBound++
+					}
+					if boundInfo.hasStructBound {
+						counters.FuncGenericStructBound++
+					}
+					if hasInlineTypeSet(node.Type.TypeParams, typeSets) {
+						counters.FuncGenericInlineUnion++
+					}
 				}
 			}
 			if node.Recv != nil {
@@ -143,7 +553,7 @@ func analyzeASTAndGetCounters(file *ast.File, typeBoundsInfo map[string]TypeBoun
 						counters.MethodWithGenericReceiver++
 
 						// Erweiterung 3: Check if receiver type has non-trivial bound
-						if info, exists := typeBoundsInfo[receiverTypeName]; exists {
+						if info, exists := pkgInfo.typeBoundsInfo[receiverTypeName]; exists {
 							if info.hasNonTrivialBound {
 								counters.MethodWithGenericReceiverNonTrivialTypeBound++
 							} else {
@@ -157,8 +567,23 @@ func analyzeASTAndGetCounters(file *ast.File, typeBoundsInfo map[string]TypeBoun
 		// Typ-Deklarationen (Structs, Aliase, Interfaces, ...)
 		case *ast.TypeSpec:
 			counters.TypeDecl++
-			if node.TypeParams != nil && len(node.TypeParams.List) > 0 {
+			isGeneric := node.TypeParams != nil && len(node.TypeParams.List) > 0
+			countInlineTypeSets(node.TypeParams, typeSets, &counters)
+
+			// Aliase (type A = B) werden getrennt von generischen Typdefinitionen gezählt
+			if node.Assign.IsValid() {
+				counters.AliasTotal++
+				if isGeneric {
+					counters.AliasGeneric++
+				} else if isInstantiatedType(node.Type) {
+					counters.AliasOfInstantiatedGeneric++
+				}
+			} else if isGeneric {
 				counters.GenericTypeDecl++
+				countGenericTypeShape(node, &counters)
+			}
+			if _, ok := node.Type.(*ast.InterfaceType); ok && !node.Assign.IsValid() {
+				counters.InterfaceTotal++
 			}
 
 			// Structs zählen
@@ -168,7 +593,7 @@ func analyzeASTAndGetCounters(file *ast.File, typeBoundsInfo map[string]TypeBoun
 					counters.StructGeneric++
 
 					// Use collected type bounds info from first pass
-					if info, exists := typeBoundsInfo[node.Name.Name]; exists {
+					if info, exists := pkgInfo.typeBoundsInfo[node.Name.Name]; exists {
 						// Erweiterung 1: Count structs with non-trivial bounds
 						if info.hasNonTrivialBound {
 							counters.StructGenericBound++
@@ -182,19 +607,22 @@ func analyzeASTAndGetCounters(file *ast.File, typeBoundsInfo map[string]TypeBoun
 				}
 			}
 
-			// Interfaces auf TypeSets prüfen
-			iface, ok := node.Type.(*ast.InterfaceType)
-			if ok && iface.Methods != nil {
-				for _, field := range iface.Methods.List {
-					// Ein Type Set im AST ist ein BinaryExpr mit '|' oder '&'
-					if _, ok := field.Type.(*ast.BinaryExpr); ok {
-						counters.GenericTypeSet++
-					}
+			// Interfaces auf Type Sets prüfen: Unions, einzelne Terme (~int) und eingebettete Constraint-Interfaces
+			if iface, ok := node.Type.(*ast.InterfaceType); ok {
+				if info := typeSets.analyzeInterface(iface); info.isTypeSet() {
+					counters.GenericTypeSet++
+					countTypeSet(info, &counters)
 				}
 			}
 		}
 		return true
 	})
 
+	// Instanziierungen generischer Funktionen und Typen
+	countInstantiations(file, pkgInfo, &counters)
+
+	// Iteratoren (range over func, Go 1.23)
+	countIterators(file, pkgInfo, &counters)
+
 	return counters, nil
 }
@@ -0,0 +1,821 @@
+package main
+
+import (
+	"GoParser/model"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeFilesResolvesBoundsAcrossFiles(t *testing.T) {
+	files := []model.SourceFile{
+		{Path: "box/box.go", Content: `package box
+
+type Box[T comparable] struct {
+	value T
+}
+
+type Bag[T any] struct {
+	items []T
+}
+`},
+		{Path: "box/box_methods.go", Content: `package box
+
+func (b Box[T]) Get() T {
+	return b.value
+}
+
+func (b *Bag[T]) Add(item T) {
+	b.items = append(b.items, item)
+}
+`},
+		// Gleicher Typname in einem anderen Paket darf nicht vermischt werden
+		{Path: "other/other.go", Content: `package other
+
+type Box[T any] struct {
+	value T
+}
+`},
+	}
+
+	result, err := NewASTAnalyzer().AnalyzeFiles(files)
+	counters := result.Counters
+	if err != nil {
+		t.Fatalf("failed to analyze files: %v", err)
+	}
+
+	if counters.MethodWithGenericReceiver != 2 {
+		t.Errorf("MethodWithGenericReceiver = %d, want 2", counters.MethodWithGenericReceiver)
+	}
+	if counters.MethodWithGenericReceiverNonTrivialTypeBound != 1 {
+		t.Errorf("MethodWithGenericReceiverNonTrivialTypeBound = %d, want 1", counters.MethodWithGenericReceiverNonTrivialTypeBound)
+	}
+	if counters.MethodWithGenericReceiverTrivialTypeBound != 1 {
+		t.Errorf("MethodWithGenericReceiverTrivialTypeBound = %d, want 1", counters.MethodWithGenericReceiverTrivialTypeBound)
+	}
+	if counters.StructGenericBound != 1 {
+		t.Errorf("StructGenericBound = %d, want 1", counters.StructGenericBound)
+	}
+}
+
+func TestAnalyzeFilesSkipsUnparsableFiles(t *testing.T) {
+	files := []model.SourceFile{
+		{Path: "a.go", Content: "package a\n\nfunc F[T any](v T) T { return v }\n"},
+		{Path: "broken.go", Content: "package a\n\nfunc {"},
+	}
+
+	result, err := NewASTAnalyzer().AnalyzeFiles(files)
+	counters := result.Counters
+	if err == nil {
+		t.Errorf("expected parse error for broken.go")
+	}
+	if counters.FuncGeneric != 1 {
+		t.Errorf("FuncGeneric = %d, want 1", counters.FuncGeneric)
+	}
+}
+
+func TestAnalyzeFilesCountsExplicitInstantiations(t *testing.T) {
+	files := []model.SourceFile{
+		{Path: "a.go", Content: `package a
+
+import "example.com/lists"
+
+type List[T any] struct {
+	items []T
+}
+
+func Map[T, U any](in []T, f func(T) U) []U { return nil }
+
+func (l *List[T]) Len() int { return len(l.items) }
+
+func use(values []int, other lists.Set[string]) {
+	_ = Map[int, string](values, nil)
+	_ = List[int]{}
+	var remote lists.List[int]
+	_ = values[0]
+	_ = remote
+}
+`},
+	}
+
+	result, err := NewASTAnalyzer().AnalyzeFiles(files)
+	counters := result.Counters
+	if err != nil {
+		t.Fatalf("failed to analyze files: %v", err)
+	}
+
+	// Map[int, string], List[int], lists.Set[string], lists.List[int]; values[0] and the receiver are no instantiations
+	if counters.InstantiationExplicit != 4 {
+		t.Errorf("InstantiationExplicit = %d, want 4", counters.InstantiationExplicit)
+	}
+	if counters.InstantiationSamePackage != 2 {
+		t.Errorf("InstantiationSamePackage = %d, want 2", counters.InstantiationSamePackage)
+	}
+	if counters.InstantiationCrossPackage != 2 {
+		t.Errorf("InstantiationCrossPackage = %d, want 2", counters.InstantiationCrossPackage)
+	}
+}
+
+func TestAnalyzeFilesReturnsDeclarations(t *testing.T) {
+	files := []model.SourceFile{
+		{Path: "pkg/a.go", Content: `package pkg
+
+type Set[K comparable, V any] map[K]V
+
+func Keys[K comparable, V any](m Set[K, V]) []K { return nil }
+
+type Box[T any] struct{ value T }
+`},
+	}
+
+	result, err := NewASTAnalyzer().AnalyzeFiles(files)
+	if err != nil {
+		t.Fatalf("failed to analyze files: %v", err)
+	}
+
+	if len(result.Declarations) != 3 {
+		t.Fatalf("got %d declarations, want 3", len(result.Declarations))
+	}
+	keys := result.Declarations[1]
+	if keys.Name != "Keys" || keys.Kind != model.DeclarationKindFunc || keys.File != "pkg/a.go" || keys.Line != 5 {
+		t.Errorf("unexpected declaration %+v", keys)
+	}
+	if len(keys.TypeParams) != 2 || keys.Constraints[0] != "comparable" || !keys.NonTrivial {
+		t.Errorf("unexpected type parameters of %+v", keys)
+	}
+	if box := result.Declarations[2]; box.Kind != model.DeclarationKindStruct || box.NonTrivial {
+		t.Errorf("unexpected declaration %+v", box)
+	}
+}
+
+func TestAnalyzeFilesClassifiesConstraintKinds(t *testing.T) {
+	files := []model.SourceFile{
+		{Path: "catalog/constraints.go", Content: `package catalog
+
+type Number interface {
+	~int | ~float64
+}
+
+type Signed interface {
+	Number
+	Sign() int
+}
+
+type Stringer interface {
+	String() string
+}
+
+type Comparer[T any] interface {
+	Compare(other T) int
+}
+
+type Point struct{ X, Y int }
+`},
+		{Path: "catalog/funcs.go", Content: `package catalog
+
+import (
+	"cmp"
+	"fmt"
+	xconstraints "golang.org/x/exp/constraints"
+)
+
+func A[T any, U interface{}](t T, u U)          {}
+func B[K comparable, V cmp.Ordered](k K, v V)   {}
+func C[T xconstraints.Integer](t T)             {}
+func D[T ~int | ~string, S interface{ ~[]T }](t T, s S) {}
+func E[T Stringer, U interface{ Len() int }](t T, u U) {}
+func F[T Number, S Signed](t T, s S)            {}
+func G[T Comparer[T]](t T)                      {}
+func H[T Point, I int](t T, i I)                {}
+func J[T fmt.Stringer](t T)                     {}
+func K[T xconstraints.Ordered, U xconstraints.Float](t T, u U) {}
+`},
+	}
+
+	result, err := NewASTAnalyzer().AnalyzeFiles(files)
+	if err != nil {
+		t.Fatalf("failed to analyze files: %v", err)
+	}
+
+	counters := result.Counters
+	for name, got := range map[string][2]int{
+		"ConstraintAny":              {counters.ConstraintAny, 3}, // A (2) und Comparer
+		"ConstraintComparable":       {counters.ConstraintComparable, 1},
+		"ConstraintOrdered":          {counters.ConstraintOrdered, 2}, // cmp.Ordered und constraints.Ordered
+		"ConstraintInlineUnion":      {counters.ConstraintInlineUnion, 2},
+		"ConstraintMethodInterface":  {counters.ConstraintMethodInterface, 2},
+		"ConstraintTypeSetInterface": {counters.ConstraintTypeSetInterface, 4}, // F (2), constraints.Integer und constraints.Float
+		"ConstraintSelfReferential":  {counters.ConstraintSelfReferential, 1},
+		"ConstraintConcrete":         {counters.ConstraintConcrete, 2},
+		"ConstraintOther":            {counters.ConstraintOther, 1}, // fmt.Stringer ohne go/types
+	} {
+		if got[0] != got[1] {
+			t.Errorf("%s = %d, want %d", name, got[0], got[1])
+		}
+	}
+
+	frequencies := constraintFrequencies(result.Declarations)
+	for _, frequency := range frequencies {
+		if frequency.Constraint == "any" && (frequency.Count != 2 || frequency.Kind != model.ConstraintKindAny) {
+			t.Errorf("frequency of any = %+v, want 2 uses of kind any", frequency)
+		}
+	}
+}
+
+func TestAnalyzeFileCountsAliases(t *testing.T) {
+	result, err := NewASTAnalyzer().AnalyzeFile(`package aliases
+
+type List[T any] struct{ items []T }
+
+type Set[T comparable] = map[T]struct{}
+
+type Vec[T any] = List[T]
+
+type IntList = List[int]
+
+type Name = string
+`)
+	if err != nil {
+		t.Fatalf("failed to analyze file: %v", err)
+	}
+
+	counters := result.Counters
+	if counters.AliasTotal != 4 || counters.AliasGeneric != 2 || counters.AliasOfInstantiatedGeneric != 1 {
+		t.Errorf("got AliasTotal=%d AliasGeneric=%d AliasOfInstantiatedGeneric=%d, want 4, 2 and 1",
+			counters.AliasTotal, counters.AliasGeneric, counters.AliasOfInstantiatedGeneric)
+	}
+	// Generische Aliase zählen nicht als generische Typdefinition
+	if counters.GenericTypeDecl != 1 || counters.TypeDecl != 5 {
+		t.Errorf("got GenericTypeDecl=%d TypeDecl=%d, want 1 and 5", counters.GenericTypeDecl, counters.TypeDecl)
+	}
+
+	kinds := make(map[string]string)
+	for _, declaration := range result.Declarations {
+		kinds[declaration.Name] = declaration.Kind
+	}
+	if kinds["Set"] != model.DeclarationKindAlias || kinds["Vec"] != model.DeclarationKindAlias {
+		t.Errorf("generic aliases have kinds %q and %q, want alias", kinds["Set"], kinds["Vec"])
+	}
+}
+
+func TestAnalyzeFileCountsGenericTypeShapes(t *testing.T) {
+	result, err := NewASTAnalyzer().AnalyzeFile(`package shapes
+
+type Repo[T any] interface {
+	Get(id string) (T, error)
+	Count() int
+}
+
+type Closer[T any] interface {
+	Close() error
+}
+
+type Plain interface {
+	Close() error
+}
+
+type Handler[T any] func(T) error
+
+type Index[K comparable, V any] map[K]V
+
+type Stack[T any] []T
+
+type Pair[T any] [2]T
+
+type Pipe[T any] chan T
+
+type Set[T comparable] = map[T]struct{}
+`)
+	if err != nil {
+		t.Fatalf("failed to analyze file: %v", err)
+	}
+
+	counters := result.Counters
+	for name, got := range map[string][2]int{
+		"InterfaceTotal":                      {counters.InterfaceTotal, 3},
+		"InterfaceGeneric":                    {counters.InterfaceGeneric, 2},
+		"InterfaceGenericMethodUsesTypeParam": {counters.InterfaceGenericMethodUsesTypeParam, 1},
+		"FuncTypeGeneric":                     {counters.FuncTypeGeneric, 1},
+		"MapTypeGeneric":                      {counters.MapTypeGeneric, 1}, // der generische Alias zählt nicht
+		"SliceTypeGeneric":                    {counters.SliceTypeGeneric, 2},
+		"ChanTypeGeneric":                     {counters.ChanTypeGeneric, 1},
+	} {
+		if got[0] != got[1] {
+			t.Errorf("%s = %d, want %d", name, got[0], got[1])
+		}
+	}
+}
+
+func TestAnalyzeFileCountsTypeSets(t *testing.T) {
+	result, err := NewASTAnalyzer().AnalyzeFile(`package sets
+
+type Signed interface {
+	~int | ~int64
+}
+
+type Unsigned interface {
+	~uint
+}
+
+type Integer interface {
+	Signed | Unsigned
+}
+
+type SmallSigned interface {
+	Signed
+	int8 | int16
+}
+
+type StringableInt interface {
+	~int
+	String() string
+}
+
+type Reader interface {
+	Read(p []byte) (int, error)
+}
+
+func Sum[T ~int | ~float64](values []T) T { var zero T; return zero }
+
+func Parse[T interface{ ~string }](s T) {}
+
+func Max[T Integer](a, b T) T { return a }
+`)
+	if err != nil {
+		t.Fatalf("failed to analyze file: %v", err)
+	}
+
+	counters := result.Counters
+	for name, got := range map[string][2]int{
+		"GenericTypeSet":      {counters.GenericTypeSet, 5}, // alle außer Reader
+		"TypeSetInline":       {counters.TypeSetInline, 2},
+		"TypeSetTerms":        {counters.TypeSetTerms, 9},
+		"TypeSetTildeTerms":   {counters.TypeSetTildeTerms, 7},
+		"TypeSetExactTerms":   {counters.TypeSetExactTerms, 2},
+		"TypeSetEmbedded":     {counters.TypeSetEmbedded, 2}, // Integer und SmallSigned
+		"TypeSetIntersection": {counters.TypeSetIntersection, 1},
+		"TypeSetMixed":        {counters.TypeSetMixed, 1},
+	} {
+		if got[0] != got[1] {
+			t.Errorf("%s = %d, want %d", name, got[0], got[1])
+		}
+	}
+}
+
+func TestAnalyzeFileClassifiesFuncTypeParams(t *testing.T) {
+	result, err := NewASTAnalyzer().AnalyzeFile(`package funcs
+
+type Empty interface{}
+
+type Point struct{ X, Y int }
+
+func Identity[T any](v T) T { return v }
+
+func Wrap[T interface{}, U Empty](v T, u U) {}
+
+func Keys[K comparable, V any](m map[K]V) []K { return nil }
+
+func Sum[T ~int | ~float64](values []T) T { var zero T; return zero }
+
+func Trim[S interface{ ~string }](s S) S { return s }
+
+func Move[P Point](p P) P { return p }
+
+func plain() {}
+`)
+	if err != nil {
+		t.Fatalf("failed to analyze file: %v", err)
+	}
+
+	counters := result.Counters
+	for name, got := range map[string][2]int{
+		"FuncGeneric":                {counters.FuncGeneric, 6},
+		"FuncGenericTrivialBound":    {counters.FuncGenericTrivialBound, 2},
+		"FuncGenericNonTrivialBound": {counters.FuncGenericNonTrivialBound, 4},
+		"FuncGenericInlineUnion":     {counters.FuncGenericInlineUnion, 2},
+		"FuncGenericStructBound":     {counters.FuncGenericStructBound, 1},
+	} {
+		if got[0] != got[1] {
+			t.Errorf("%s = %d, want %d", name, got[0], got[1])
+		}
+	}
+}
+
+func TestAnalyzeFileCollectsTypeParamStats(t *testing.T) {
+	result, err := NewASTAnalyzer().AnalyzeFile(`package stats
+
+type List[T any] struct{ items []T }
+
+type Phantom[T any] struct{ id int }
+
+func Identity[T any](v T) T { return v }
+
+func Make[T any]() T { var zero T; return zero }
+
+func Index[S ~[]E, E comparable](s S, v E) int { return -1 }
+
+func Ignore[A, B, C any, D comparable](a A) {}
+`)
+	if err != nil {
+		t.Fatalf("failed to analyze file: %v", err)
+	}
+
+	usages := map[string][]string{}
+	for _, declaration := range result.Declarations {
+		usages[declaration.Name] = declaration.TypeParamUsages
+	}
+	if got := usages["Identity"]; len(got) != 1 || got[0] != "params,results" {
+		t.Errorf("usages of Identity = %v, want [params,results]", got)
+	}
+	if got := usages["Make"]; len(got) != 1 || got[0] != "results,body" {
+		t.Errorf("usages of Make = %v, want [results,body]", got)
+	}
+	if got := usages["Index"]; len(got) != 2 || got[0] != "params" || got[1] != "params,constraint" {
+		t.Errorf("usages of Index = %v, want [params params,constraint]", got)
+	}
+	if got := usages["Phantom"]; len(got) != 1 || got[0] != "unused" {
+		t.Errorf("usages of Phantom = %v, want [unused]", got)
+	}
+
+	stats := result.TypeParams
+	for bucket, want := range map[string]int{"1": 4, "2": 1, "3": 0, "4+": 1} {
+		if stats.Arity[bucket] != want {
+			t.Errorf("arity %s = %d, want %d", bucket, stats.Arity[bucket], want)
+		}
+	}
+	// Phantom.T sowie B, C und D von Ignore
+	if stats.Usage[model.TypeParamUsageUnused] != 4 {
+		t.Errorf("unused type params = %d, want 4", stats.Usage[model.TypeParamUsageUnused])
+	}
+}
+
+func TestAnalyzeFileFindsGenericCandidates(t *testing.T) {
+	result, err := NewASTAnalyzer().AnalyzeFile(`package candidates
+
+import "fmt"
+
+func Describe(v interface{}) string {
+	switch x := v.(type) {
+	case int:
+		return fmt.Sprint(x)
+	}
+	return ""
+}
+
+func AsInt(v any) int {
+	n, _ := v.(int)
+	return n
+}
+
+func Print(v any) {
+	fmt.Println(v)
+}
+
+func Logf(format string, args ...any) {}
+
+func SumInts(values []int) int {
+	var sum int
+	for _, v := range values {
+		sum += v
+	}
+	return sum
+}
+
+func SumFloats(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum
+}
+
+func CountInts(values []int) int {
+	var sum int
+	for range values {
+		sum++
+	}
+	return sum
+}
+
+type byName []string
+
+func (b byName) Len() int           { return len(b) }
+func (b byName) Less(i, j int) bool { return b[i] < b[j] }
+func (b byName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+
+type partial []int
+
+func (p partial) Len() int { return len(p) }
+`)
+	if err != nil {
+		t.Fatalf("failed to analyze file: %v", err)
+	}
+
+	counters := result.Counters
+	for name, got := range map[string][2]int{
+		"CandidateAnyTypeSwitch":   {counters.CandidateAnyTypeSwitch, 2},
+		"CandidateDuplicateFunc":   {counters.CandidateDuplicateFunc, 2},
+		"CandidateDuplicateFamily": {counters.CandidateDuplicateFamily, 1},
+		"CandidateSortInterface":   {counters.CandidateSortInterface, 1},
+	} {
+		if got[0] != got[1] {
+			t.Errorf("%s = %d, want %d", name, got[0], got[1])
+		}
+	}
+
+	var sites []string
+	for _, candidate := range result.Candidates {
+		sites = append(sites, candidate.Kind+":"+candidate.Name+":"+candidate.Detail)
+	}
+	want := []string{"any_type_switch:Describe:v", "any_type_switch:AsInt:v", "duplicate_func:SumInts:SumFloats,SumInts", "duplicate_func:SumFloats:SumFloats,SumInts", "sort_interface:byName:"}
+	if strings.Join(sites, " ") != strings.Join(want, " ") {
+		t.Errorf("candidates = %v, want %v", sites, want)
+	}
+}
+
+func TestAnalyzeFileFindsDuplicateFamiliesWithDifferentNames(t *testing.T) {
+	result, err := NewASTAnalyzer().AnalyzeFile(`package candidates
+
+func SumInts(xs []int) int {
+	var s int
+	for _, x := range xs {
+		s += x
+	}
+	return s
+}
+
+func SumFloats(nums []float64) (total float64) {
+	for _, n := range nums {
+		total += n
+	}
+	return total
+}
+
+func SumUints(values []uint) uint {
+	var sum uint
+	for _, v := range values {
+		sum += v
+	}
+	return sum
+}
+
+func MinInts(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func MinFloats(x, y float64) float64 {
+	if x < y {
+		return x
+	}
+	return y
+}
+
+func MaxStrings(a, b string) string {
+	if a < b {
+		return b
+	}
+	return a
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to analyze file: %v", err)
+	}
+
+	// SumFloats deklariert sum als benanntes Ergebnis und hat daher eine andere Struktur als SumInts und SumUints;
+	// MaxStrings unterscheidet sich von MinInts und MinFloats nur in der Reihenfolge der Parameter
+	var sites []string
+	for _, candidate := range result.Candidates {
+		sites = append(sites, candidate.Name+":"+candidate.Detail)
+	}
+	want := []string{"SumInts:SumInts,SumUints", "SumUints:SumInts,SumUints", "MinInts:MinFloats,MinInts", "MinFloats:MinFloats,MinInts"}
+	if strings.Join(sites, " ") != strings.Join(want, " ") {
+		t.Errorf("candidates = %v, want %v", sites, want)
+	}
+	if result.Counters.CandidateDuplicateFamily != 2 {
+		t.Errorf("CandidateDuplicateFamily = %d, want 2", result.Counters.CandidateDuplicateFamily)
+	}
+}
+
+func TestAnalyzeFileCountsGenericAPIUsage(t *testing.T) {
+	result, err := NewASTAnalyzer().AnalyzeFile(`package consumer
+
+import (
+	"cmp"
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/exp/constraints"
+)
+
+type cache struct {
+	mu      sync.Mutex
+	current atomic.Pointer[string]
+	hits    atomic.Int64
+}
+
+var loadConfig = sync.OnceValue(func() string { return "config" })
+
+func Max[T constraints.Ordered](a, b T) T { return max(a, b) }
+
+func use(values []int, m map[string]int) bool {
+	slices.Sort(values)
+	keys := slices.Collect(maps.Keys(m))
+	_ = cmp.Compare(1, 2)
+	_ = reflect.ValueOf(keys)
+	return slices.Contains(values, 3)
+}
+
+func shadowed(slices []int) int { return len(slices) }
+`)
+	if err != nil {
+		t.Fatalf("failed to analyze file: %v", err)
+	}
+
+	counters := result.Counters
+	// cmp, maps, slices, sync, sync/atomic und constraints; reflect nur ohne generische API
+	if counters.GenericAPIImports != 6 {
+		t.Errorf("GenericAPIImports = %d, want 6", counters.GenericAPIImports)
+	}
+	if counters.GenericAPIUses != 8 {
+		t.Errorf("GenericAPIUses = %d, want 8", counters.GenericAPIUses)
+	}
+
+	uses := result.GenericAPIs.Uses
+	if uses["slices"]["Sort"] != 1 || uses["slices"]["Contains"] != 1 || uses["slices"]["Collect"] != 1 {
+		t.Errorf("slices uses = %v, want Sort, Collect and Contains once", uses["slices"])
+	}
+	if uses["sync/atomic"]["Pointer"] != 1 || uses["sync/atomic"]["Int64"] != 0 {
+		t.Errorf("sync/atomic uses = %v, want only Pointer", uses["sync/atomic"])
+	}
+	if uses["sync"]["OnceValue"] != 1 || uses["sync"]["Mutex"] != 0 {
+		t.Errorf("sync uses = %v, want only OnceValue", uses["sync"])
+	}
+	if _, ok := result.GenericAPIs.Imports["reflect"]; ok {
+		t.Errorf("reflect counted as generic API import without generic use")
+	}
+}
+
+func TestAnalyzeFileCountsIterators(t *testing.T) {
+	result, err := NewASTAnalyzer().AnalyzeFile(`package iterators
+
+import (
+	"iter"
+	"maps"
+	"slices"
+)
+
+type List[T any] struct{ items []T }
+
+func (l *List[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {}
+}
+
+func Count(n int) func(yield func(int) bool) {
+	return func(yield func(int) bool) {}
+}
+
+func Countdown(yield func(int) bool) {}
+
+func use(l *List[string], m map[string]int, values []int) {
+	for i, v := range l.All() {
+		_, _ = i, v
+	}
+	for i := range Count(3) {
+		_ = i
+	}
+	for i := range Countdown {
+		_ = i
+	}
+	for k := range maps.Keys(m) {
+		_ = k
+	}
+	seq := slices.Values(values)
+	for v := range seq {
+		_ = v
+	}
+	for i := range values {
+		_ = i
+	}
+	for i := range 10 {
+		_ = i
+	}
+	next, stop := iter.Pull(seq)
+	defer stop()
+	_, _ = next()
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to analyze file: %v", err)
+	}
+
+	counters := result.Counters
+	for name, got := range map[string][2]int{
+		"IterSeqFunc":   {counters.IterSeqFunc, 2},
+		"RangeOverFunc": {counters.RangeOverFunc, 5},
+		"IterPull":      {counters.IterPull, 1},
+	} {
+		if got[0] != got[1] {
+			t.Errorf("%s = %d, want %d", name, got[0], got[1])
+		}
+	}
+}
+
+func TestAnalyzeFilesCountsPerFileCategory(t *testing.T) {
+	files := []model.SourceFile{
+		{Path: "list/list.go", Content: "package list\n\nfunc Map[T, U any](s []T, f func(T) U) []U { return nil }\n"},
+		{Path: "list/list_test.go", Content: "package list\n\nfunc helper[T any](v T) T { return v }\n\nfunc TestMap() {}\n"},
+		{Path: "list/zz_generated.go", Content: "// Code generated by gen. DO NOT EDIT.\n\npackage list\n\nfunc Filter[T any](s []T) []T { return s }\n"},
+		{Path: "vendor/example.com/dep/dep.go", Content: "package dep\n\nfunc Keys[K comparable, V any](m map[K]V) []K { return nil }\n"},
+	}
+
+	result, err := NewASTAnalyzer().AnalyzeFiles(files)
+	if err != nil {
+		t.Fatalf("failed to analyze files: %v", err)
+	}
+
+	for category, want := range map[string]int{
+		model.FileCategoryProduction: 1,
+		model.FileCategoryTest:       1,
+		model.FileCategoryGenerated:  1,
+		model.FileCategoryVendored:   1,
+		model.FileCategoryExample:    0,
+	} {
+		if got := result.ByCategory[category].FuncGeneric; got != want {
+			t.Errorf("FuncGeneric of %s = %d, want %d", category, got, want)
+		}
+	}
+	if result.Counters.FuncGeneric != 4 || result.Counters.FuncTotal != 5 {
+		t.Errorf("FuncGeneric/FuncTotal = %d/%d, want 4/5", result.Counters.FuncGeneric, result.Counters.FuncTotal)
+	}
+}
+
+func TestAnalyzeFilesCountsPerModule(t *testing.T) {
+	files := []model.SourceFile{
+		{Path: "go.mod", Content: "module k8s.io/kubernetes\n\ngo 1.22.0\n"},
+		{Path: "pkg/util/sets.go", Content: "package util\n\nfunc Keys[K comparable, V any](m map[K]V) []K { return nil }\n"},
+		{Path: "staging/src/k8s.io/api/go.mod", Content: "module k8s.io/api\n\ngo 1.21\n"},
+		{Path: "staging/src/k8s.io/api/core/types.go", Content: "package core\n\ntype List[T any] struct{ items []T }\n\nfunc Map[T, U any](s []T, f func(T) U) []U { return nil }\n"},
+		// go.mod-Dateien unter testdata bilden kein eigenes Modul
+		{Path: "pkg/util/testdata/go.mod", Content: "module example.com/fixture\n"},
+		{Path: "pkg/util/testdata/fixture.go", Content: "package fixture\n\nfunc Fixture[T any]() {}\n"},
+	}
+
+	result, err := NewASTAnalyzer().AnalyzeFiles(files)
+	if err != nil {
+		t.Fatalf("failed to analyze files: %v", err)
+	}
+
+	if len(result.ByModule) != 2 {
+		t.Fatalf("got %d modules, want 2: %v", len(result.ByModule), result.ByModule)
+	}
+	root, staging := result.ByModule["."], result.ByModule["staging/src/k8s.io/api"]
+	if root.ModulePath != "k8s.io/kubernetes" || root.GoVersion != "1.22.0" || root.Counters.FuncGeneric != 2 {
+		t.Errorf("root module = %s (go %s) with %d generic functions, want k8s.io/kubernetes (go 1.22.0) with 2", root.ModulePath, root.GoVersion, root.Counters.FuncGeneric)
+	}
+	if staging.ModulePath != "k8s.io/api" || staging.GoVersion != "1.21" || staging.Counters.FuncGeneric != 1 || staging.Counters.StructGeneric != 1 {
+		t.Errorf("staging module = %s (go %s) with %d generic functions and %d generic structs, want k8s.io/api (go 1.21) with 1 and 1",
+			staging.ModulePath, staging.GoVersion, staging.Counters.FuncGeneric, staging.Counters.StructGeneric)
+	}
+	if result.Counters.FuncGeneric != root.Counters.FuncGeneric+staging.Counters.FuncGeneric {
+		t.Errorf("repository rollup has %d generic functions, want the sum of all modules", result.Counters.FuncGeneric)
+	}
+}
+
+func TestAnalyzeFilesRecordsGoDirectives(t *testing.T) {
+	module := []model.SourceFile{
+		{Path: "go.mod", Content: "module example.com/lib\n\ngo 1.22.0\n\ntoolchain go1.22.3\n"},
+		{Path: "lib.go", Content: "package lib\n\nfunc Map[T, U any](s []T, f func(T) U) []U { return nil }\n"},
+	}
+	result, err := NewASTAnalyzer().AnalyzeFiles(module)
+	if err != nil {
+		t.Fatalf("failed to analyze files: %v", err)
+	}
+	want := model.GoDirectives{ModulePath: "example.com/lib", GoVersion: "1.22.0", Toolchain: "go1.22.3"}
+	if result.Go != want || result.ByModule["."].GoDirectives != want {
+		t.Errorf("got repository %+v and module %+v, want %+v", result.Go, result.ByModule["."].GoDirectives, want)
+	}
+
+	// Ein go.work im Wurzelverzeichnis bestimmt die Go-Version des ganzen Repositories
+	workspace := []model.SourceFile{
+		{Path: "go.work", Content: "go 1.23\n\ntoolchain go1.23.1\n\nuse (\n\t./a\n\t./b\n)\n"},
+		{Path: "a/go.mod", Content: "module example.com/a\n\ngo 1.17\n"},
+		{Path: "a/a.go", Content: "package a\n\nfunc A() {}\n"},
+		{Path: "b/go.mod", Content: "module example.com/b\n\ngo 1.21\n"},
+		{Path: "b/b.go", Content: "package b\n\ntype Set[T comparable] map[T]struct{}\n"},
+	}
+	result, err = NewASTAnalyzer().AnalyzeFiles(workspace)
+	if err != nil {
+		t.Fatalf("failed to analyze files: %v", err)
+	}
+	if want := (model.GoDirectives{GoVersion: "1.23", Toolchain: "go1.23.1"}); result.Go != want {
+		t.Errorf("got repository %+v, want %+v", result.Go, want)
+	}
+	if a := result.ByModule["a"]; a.ModulePath != "example.com/a" || a.GoVersion != "1.17" {
+		t.Errorf("module a = %+v, want example.com/a with go 1.17", a.GoDirectives)
+	}
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"GoParser/model"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// candidateCollector finds code that could use generics but does not. Families of duplicate
+// functions and sort.Interface implementations can span several files, so the collector is
+// filled file by file during the package walk and evaluated once per package.
+type candidateCollector struct {
+	fset       *token.FileSet
+	pkgInfo    *packageInfo
+	candidates []model.GenericCandidate
+	shapes     map[string][]shapedFunc        // Fingerprint -> Funktionen mit diesem Fingerprint
+	sortTypes  map[string]map[string]ast.Node // Receiver-Typ -> gefundene Methoden von sort.Interface
+}
+
+// shapedFunc is a non-generic function together with the type names replaced in its fingerprint
+type shapedFunc struct {
+	decl      *ast.FuncDecl
+	typeNames string
+}
+
+func newCandidateCollector(fset *token.FileSet, pkgInfo *packageInfo) *candidateCollector {
+	return &candidateCollector{
+		fset:      fset,
+		pkgInfo:   pkgInfo,
+		shapes:    make(map[string][]shapedFunc),
+		sortTypes: make(map[string]map[string]ast.Node),
+	}
+}
+
+// addFile inspects the package level functions and methods of a file
+func (c *candidateCollector) addFile(file *ast.File) {
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || (fn.Type.TypeParams != nil && len(fn.Type.TypeParams.List) > 0) {
+			continue
+		}
+		if fn.Recv != nil {
+			c.addSortMethod(fn)
+			continue
+		}
+		// Methoden können keine eigenen Typparameter haben und werden daher nur für sort.Interface betrachtet
+		if param := anyParamAssertedFirst(fn, c.pkgInfo.typeSpecs); param != "" {
+			c.add(fn.Name, fn.Name.Name, model.CandidateKindAnyTypeSwitch, param)
+		}
+		if fn.Body != nil && fn.Type.Params.NumFields() > 0 {
+			shape, typeNames := functionShape(fn, c.pkgInfo.typeSpecs)
+			if len(typeNames) > 0 {
+				c.shapes[shape] = append(c.shapes[shape], shapedFunc{decl: fn, typeNames: strings.Join(typeNames, ",")})
+			}
+		}
+	}
+}
+
+func (c *candidateCollector) add(node ast.Node, name, kind, detail string) {
+	position := c.fset.Position(node.Pos())
+	c.candidates = append(c.candidates, model.GenericCandidate{
+		File:   position.Filename,
+		Line:   position.Line,
+		Name:   name,
+		Kind:   kind,
+		Detail: detail,
+	})
+}
+
+// result evaluates the package wide detectors and returns all candidates ordered by position
+func (c *candidateCollector) result() []model.GenericCandidate {
+	// Familien: gleicher Fingerprint, aber mindestens zwei verschiedene Typen
+	for _, funcs := range c.shapes {
+		typeVariants := make(map[string]bool)
+		for _, f := range funcs {
+			typeVariants[f.typeNames] = true
+		}
+		if len(typeVariants) < 2 {
+			continue
+		}
+		names := make([]string, 0, len(funcs))
+		for _, f := range funcs {
+			names = append(names, f.decl.Name.Name)
+		}
+		sort.Strings(names)
+		family := strings.Join(names, ",")
+		for _, f := range funcs {
+			c.add(f.decl.Name, f.decl.Name.Name, model.CandidateKindDuplicateFunc, family)
+		}
+	}
+
+	for typeName, methods := range c.sortTypes {
+		if len(methods) < 3 {
+			continue
+		}
+		var node ast.Node = methods["Len"]
+		if ts, ok := c.pkgInfo.typeSpecs[typeName]; ok {
+			node = ts.Name
+		}
+		c.add(node, typeName, model.CandidateKindSortInterface, "")
+	}
+
+	sort.SliceStable(c.candidates, func(i, j int) bool {
+		a, b := c.candidates[i], c.candidates[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Name < b.Name
+	})
+	return c.candidates
+}
+
+// addSortMethod records fn if it has the signature of a method of sort.Interface
+func (c *candidateCollector) addSortMethod(fn *ast.FuncDecl) {
+	params, results := fn.Type.Params.NumFields(), fn.Type.Results.NumFields()
+	var ok bool
+	switch fn.Name.Name {
+	case "Len":
+		ok = params == 0 && results == 1 && isIdentNamed(fn.Type.Results.List[0].Type, "int")
+	case "Less":
+		ok = params == 2 && allFieldsOfType(fn.Type.Params, "int") && results == 1 && isIdentNamed(fn.Type.Results.List[0].Type, "bool")
+	case "Swap":
+		ok = params == 2 && allFieldsOfType(fn.Type.Params, "int") && results == 0
+	}
+	if !ok || len(fn.Recv.List) == 0 {
+		return
+	}
+
+	receiverType := fn.Recv.List[0].Type
+	if star, isStar := receiverType.(*ast.StarExpr); isStar {
+		receiverType = star.X
+	}
+	ident, isIdent := receiverType.(*ast.Ident)
+	if !isIdent {
+		return
+	}
+	if c.sortTypes[ident.Name] == nil {
+		c.sortTypes[ident.Name] = make(map[string]ast.Node)
+	}
+	c.sortTypes[ident.Name][fn.Name.Name] = fn.Name
+}
+
+func isIdentNamed(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
+
+func allFieldsOfType(fields *ast.FieldList, name string) bool {
+	for _, field := range fields.List {
+		if !isIdentNamed(field.Type, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// anyParamAssertedFirst returns the name of an any/interface{} parameter of fn on which the first
+// statement of the body does a type switch or type assertion, or "" if there is none.
+// Variadic parameters (...any) are left out, they are usually passed on like in fmt.Printf.
+func anyParamAssertedFirst(fn *ast.FuncDecl, typeSpecs map[string]*ast.TypeSpec) string {
+	if fn.Body == nil || len(fn.Body.List) == 0 {
+		return ""
+	}
+	anyParams := make(map[string]bool)
+	for _, field := range fn.Type.Params.List {
+		if isEmptyInterface(field.Type, typeSpecs) {
+			for _, name := range field.Names {
+				anyParams[name.Name] = true
+			}
+		}
+	}
+	if len(anyParams) == 0 {
+		return ""
+	}
+
+	// Nur der Kopf der ersten Anweisung zählt, nicht beliebig tief geschachtelter Code
+	var heads []ast.Node
+	switch stmt := fn.Body.List[0].(type) {
+	case *ast.TypeSwitchStmt:
+		heads = append(heads, stmt.Assign)
+	case *ast.IfStmt:
+		if stmt.Init != nil {
+			heads = append(heads, stmt.Init)
+		}
+		heads = append(heads, stmt.Cond)
+	case *ast.AssignStmt, *ast.DeclStmt, *ast.ReturnStmt:
+		heads = append(heads, stmt)
+	}
+
+	param := ""
+	for _, head := range heads {
+		ast.Inspect(head, func(n ast.Node) bool {
+			if assert, ok := n.(*ast.TypeAssertExpr); ok && param == "" {
+				if ident, ok := assert.X.(*ast.Ident); ok && anyParams[ident.Name] {
+					param = ident.Name
+				}
+			}
+			return param == ""
+		})
+	}
+	return param
+}
+
+// isEmptyInterface reports whether expr is any or interface{} (and not a declaration named any)
+func isEmptyInterface(expr ast.Expr, typeSpecs map[string]*ast.TypeSpec) bool {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name == "any" && resolveTypeSpec(e, typeSpecs) == nil
+	case *ast.InterfaceType:
+		return e.Methods == nil || e.Methods.NumFields() == 0
+	}
+	return false
+}
+
+// functionShape returns a fingerprint of the signature and body of fn in which all type names
+// (predeclared and declared in the package) are replaced by a placeholder, together with the
+// replaced type names in order of occurrence. Identifiers declared within fn (parameters, results,
+// locals, labels) are replaced by their position in order of declaration ($0, $1, ...), so the names
+// chosen for them don't matter. Functions that only differ in these types and names, like
+// SumInts(xs []int) and SumFloats(nums []float64), have the same fingerprint. The function name is not part of it.
+func functionShape(fn *ast.FuncDecl, typeSpecs map[string]*ast.TypeSpec) (string, []string) {
+	locals, selectors := localNames(fn)
+	var shape strings.Builder
+	var typeNames []string
+	visit := func(n ast.Node) bool {
+		if n == nil {
+			shape.WriteString(")")
+			return false
+		}
+		fmt.Fprintf(&shape, "(%T", n)
+		switch node := n.(type) {
+		case *ast.Ident:
+			if placeholder, ok := locals[node.Name]; ok && !selectors[node] {
+				shape.WriteString(" " + placeholder)
+			} else if predeclaredTypes[node.Name] || typeSpecs[node.Name] != nil {
+				shape.WriteString(" $T")
+				typeNames = append(typeNames, node.Name)
+			} else {
+				shape.WriteString(" " + node.Name)
+			}
+		case *ast.BasicLit:
+			shape.WriteString(" " + node.Value)
+		case *ast.BinaryExpr:
+			shape.WriteString(" " + node.Op.String())
+		case *ast.UnaryExpr:
+			shape.WriteString(" " + node.Op.String())
+		case *ast.AssignStmt:
+			shape.WriteString(" " + node.Tok.String())
+		case *ast.IncDecStmt:
+			shape.WriteString(" " + node.Tok.String())
+		case *ast.BranchStmt:
+			shape.WriteString(" " + node.Tok.String())
+		}
+		return true
+	}
+	ast.Inspect(fn.Type, visit)
+	ast.Inspect(fn.Body, visit)
+	return shape.String(), typeNames
+}
+
+// localNames assigns the placeholders $0, $1, ... to the identifiers declared within fn in order of
+// declaration. The selectors of selector expressions (x.Len) denote fields or methods, never these
+// identifiers, and are returned separately so that a local named like a field is not replaced there.
+func localNames(fn *ast.FuncDecl) (map[string]string, map[*ast.Ident]bool) {
+	locals := make(map[string]string)
+	selectors := make(map[*ast.Ident]bool)
+	declare := func(idents ...*ast.Ident) {
+		for _, ident := range idents {
+			if ident == nil || ident.Name == "_" {
+				continue
+			}
+			if _, ok := locals[ident.Name]; !ok {
+				locals[ident.Name] = fmt.Sprintf("$%d", len(locals))
+			}
+		}
+	}
+	declareFields := func(fields *ast.FieldList) {
+		if fields == nil {
+			return
+		}
+		for _, field := range fields.List {
+			declare(field.Names...)
+		}
+	}
+	inspect := func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncType:
+			declareFields(node.TypeParams)
+			declareFields(node.Params)
+			declareFields(node.Results)
+		case *ast.AssignStmt:
+			if node.Tok == token.DEFINE {
+				for _, lhs := range node.Lhs {
+					ident, _ := lhs.(*ast.Ident)
+					declare(ident)
+				}
+			}
+		case *ast.RangeStmt:
+			if node.Tok == token.DEFINE {
+				key, _ := node.Key.(*ast.Ident)
+				value, _ := node.Value.(*ast.Ident)
+				declare(key, value)
+			}
+		case *ast.ValueSpec:
+			declare(node.Names...)
+		case *ast.TypeSpec:
+			declare(node.Name)
+		case *ast.LabeledStmt:
+			declare(node.Label)
+		case *ast.SelectorExpr:
+			selectors[node.Sel] = true
+		}
+		return true
+	}
+	ast.Inspect(fn.Type, inspect)
+	ast.Inspect(fn.Body, inspect)
+	return locals, selectors
+}
+
+// countCandidate adds a candidate to the counters. Every family is identified by its members,
+// which are stored as detail of each of its functions; families holds the families counted so far.
+func countCandidate(counters *model.GenericCounters, candidate model.GenericCandidate, families map[string]bool) {
+	switch candidate.Kind {
+	case model.CandidateKindAnyTypeSwitch:
+		counters.CandidateAnyTypeSwitch++
+	case model.CandidateKindDuplicateFunc:
+		counters.CandidateDuplicateFunc++
+		if !families[candidate.Detail] {
+			families[candidate.Detail] = true
+			counters.CandidateDuplicateFamily++
+		}
+	case model.CandidateKindSortInterface:
+		counters.CandidateSortInterface++
+	}
+}
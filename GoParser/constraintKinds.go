@@ -0,0 +1,235 @@
+package main
+
+import (
+	"GoParser/model"
+	"go/ast"
+	"go/types"
+)
+
+// constraintKind classifies the constraint of the type parameter name. Self-referential
+// constraints are detected first, because they are usually instantiated interfaces like
+// Comparer[T] that would otherwise be classified by their base. Constraints that cannot be
+// resolved syntactically (e.g. imported ones) are classified with go/types if available.
+// imports maps the local import names of the file to their paths (see importPaths).
+func constraintKind(name *ast.Ident, constraint ast.Expr, imports map[string]string, pkgInfo *packageInfo) string {
+	if mentionsIdent(constraint, name.Name) {
+		return model.ConstraintKindSelfReferential
+	}
+
+	kind := classifyConstraintExpr(constraint, imports, pkgInfo, make(map[*ast.TypeSpec]bool))
+	if kind == model.ConstraintKindOther && pkgInfo.typesInfo != nil {
+		if typeName, ok := pkgInfo.typesInfo.Defs[name].(*types.TypeName); ok {
+			if typeParam, ok := typeName.Type().(*types.TypeParam); ok {
+				kind = classifyConstraintType(typeParam.Constraint())
+			}
+		}
+	}
+	return kind
+}
+
+// mentionsIdent reports whether name is used anywhere within node
+func mentionsIdent(node ast.Node, name string) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.SelectorExpr:
+			// In pkg.T bezeichnet T einen Typ eines anderen Pakets
+			found = found || mentionsIdent(node.X, name)
+			return false
+		case *ast.Ident:
+			found = found || node.Name == name
+		}
+		return !found
+	})
+	return found
+}
+
+// classifyConstraintExpr classifies a constraint by its syntax and the type declarations of the package
+func classifyConstraintExpr(expr ast.Expr, imports map[string]string, pkgInfo *packageInfo, seen map[*ast.TypeSpec]bool) string {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return classifyConstraintExpr(e.X, imports, pkgInfo, seen)
+
+	case *ast.Ident:
+		// Eigene Deklarationen haben Vorrang vor gleichnamigen vordeklarierten Bezeichnern
+		if ts := resolveTypeSpec(e, pkgInfo.typeSpecs); ts != nil {
+			return classifyConstraintTypeSpec(ts, imports, pkgInfo, seen)
+		}
+		switch e.Name {
+		case "any":
+			return model.ConstraintKindAny
+		case "comparable":
+			return model.ConstraintKindComparable
+		}
+		if predeclaredTypes[e.Name] {
+			return model.ConstraintKindConcrete
+		}
+
+	case *ast.SelectorExpr:
+		if pkg, ok := e.X.(*ast.Ident); ok {
+			switch importPath := imports[pkg.Name]; {
+			case isOrderedConstraint(importPath, e.Sel.Name):
+				return model.ConstraintKindOrdered
+			case isKnownTypeSetConstraint(importPath, e.Sel.Name):
+				// constraints.Integer, constraints.Float, ... sind benannte Interfaces mit Typ-Termen
+				return model.ConstraintKindTypeSetInterface
+			}
+		}
+
+	case *ast.IndexExpr:
+		// Instanziiertes Constraint aus dem eigenen Paket, z.B. Number[int]
+		if ident, ok := e.X.(*ast.Ident); ok {
+			if ts := resolveTypeSpec(ident, pkgInfo.typeSpecs); ts != nil {
+				return classifyConstraintTypeSpec(ts, imports, pkgInfo, seen)
+			}
+		}
+	case *ast.IndexListExpr:
+		if ident, ok := e.X.(*ast.Ident); ok {
+			if ts := resolveTypeSpec(ident, pkgInfo.typeSpecs); ts != nil {
+				return classifyConstraintTypeSpec(ts, imports, pkgInfo, seen)
+			}
+		}
+
+	case *ast.BinaryExpr, *ast.UnaryExpr:
+		// ~int | ~string bzw. ~int
+		return model.ConstraintKindInlineUnion
+
+	case *ast.InterfaceType:
+		switch {
+		case e.Methods == nil || e.Methods.NumFields() == 0:
+			return model.ConstraintKindAny
+		case interfaceHasTerms(e, imports, pkgInfo):
+			return model.ConstraintKindInlineUnion
+		}
+		return model.ConstraintKindMethodInterface
+
+	case *ast.StructType, *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.StarExpr:
+		return model.ConstraintKindConcrete
+	}
+
+	return model.ConstraintKindOther
+}
+
+// classifyConstraintTypeSpec classifies a named constraint declared in the package
+func classifyConstraintTypeSpec(ts *ast.TypeSpec, imports map[string]string, pkgInfo *packageInfo, seen map[*ast.TypeSpec]bool) string {
+	if seen[ts] {
+		return model.ConstraintKindOther
+	}
+	seen[ts] = true
+
+	// Aliase werden wie ihr Ziel behandelt
+	if ts.Assign.IsValid() {
+		return classifyConstraintExpr(ts.Type, imports, pkgInfo, seen)
+	}
+
+	iface, ok := ts.Type.(*ast.InterfaceType)
+	if !ok {
+		return model.ConstraintKindConcrete
+	}
+	switch {
+	case iface.Methods == nil || iface.Methods.NumFields() == 0:
+		return model.ConstraintKindAny
+	case interfaceHasTerms(iface, imports, pkgInfo):
+		return model.ConstraintKindTypeSetInterface
+	}
+	return model.ConstraintKindMethodInterface
+}
+
+// interfaceHasTerms reports whether an interface restricts its type set with type terms,
+// directly or through an embedded constraint interface (see typeSetAnalyzer)
+func interfaceHasTerms(iface *ast.InterfaceType, imports map[string]string, pkgInfo *packageInfo) bool {
+	return newTypeSetAnalyzer(imports, pkgInfo).analyzeInterface(iface).isTypeSet()
+}
+
+// classifyConstraintType classifies a constraint resolved by go/types, e.g. an imported interface
+func classifyConstraintType(constraint types.Type) string {
+	var obj *types.TypeName
+	switch t := constraint.(type) {
+	case *types.Named:
+		obj = t.Obj()
+	case *types.Alias:
+		obj = t.Obj()
+	}
+	if obj != nil && obj.Pkg() != nil {
+		if isOrderedConstraint(obj.Pkg().Path(), obj.Name()) {
+			return model.ConstraintKindOrdered
+		}
+	}
+
+	iface, ok := constraint.Underlying().(*types.Interface)
+	if !ok {
+		return model.ConstraintKindOther
+	}
+	switch {
+	case iface.Empty():
+		return model.ConstraintKindAny
+	case iface.IsImplicit():
+		// [T pkg.Struct] ist ein implizites Interface mit dem Typ als einzigem Term
+		if iface.NumEmbeddeds() == 1 {
+			if _, isUnion := iface.EmbeddedType(0).(*types.Union); !isUnion {
+				return model.ConstraintKindConcrete
+			}
+		}
+		return model.ConstraintKindInlineUnion
+	case iface.IsMethodSet():
+		return model.ConstraintKindMethodInterface
+	}
+	return model.ConstraintKindTypeSetInterface
+}
+
+// isOrderedConstraint reports whether name in the package importPath is cmp.Ordered or constraints.Ordered from golang.org/x/exp
+func isOrderedConstraint(importPath, name string) bool {
+	return (importPath == "cmp" || importPath == "golang.org/x/exp/constraints") && name == "Ordered"
+}
+
+// isKnownTypeSetConstraint reports whether name in the package importPath is a constraint with type terms
+// that is recognized without go/types: cmp.Ordered and every constraint of golang.org/x/exp/constraints
+func isKnownTypeSetConstraint(importPath, name string) bool {
+	return (importPath == "cmp" && name == "Ordered") || importPath == "golang.org/x/exp/constraints"
+}
+
+// countConstraintKinds adds the constraint kinds of a declaration to the counters
+func countConstraintKinds(counters *model.GenericCounters, declaration model.GenericDeclaration) {
+	for _, kind := range declaration.ConstraintKinds {
+		switch kind {
+		case model.ConstraintKindAny:
+			counters.ConstraintAny++
+		case model.ConstraintKindComparable:
+			counters.ConstraintComparable++
+		case model.ConstraintKindOrdered:
+			counters.ConstraintOrdered++
+		case model.ConstraintKindInlineUnion:
+			counters.ConstraintInlineUnion++
+		case model.ConstraintKindMethodInterface:
+			counters.ConstraintMethodInterface++
+		case model.ConstraintKindTypeSetInterface:
+			counters.ConstraintTypeSetInterface++
+		case model.ConstraintKindSelfReferential:
+			counters.ConstraintSelfReferential++
+		case model.ConstraintKindConcrete:
+			counters.ConstraintConcrete++
+		default:
+			counters.ConstraintOther++
+		}
+	}
+}
+
+// constraintFrequencies counts how often every constraint text is used in the declarations.
+// The same text can denote different kinds in different packages, so text and kind form the key.
+// The result is ordered by first occurrence.
+func constraintFrequencies(declarations []model.GenericDeclaration) []model.ConstraintFrequency {
+	index := make(map[[2]string]int)
+	var frequencies []model.ConstraintFrequency
+	for _, declaration := range declarations {
+		for i, constraint := range declaration.Constraints {
+			key := [2]string{constraint, declaration.ConstraintKinds[i]}
+			if j, ok := index[key]; ok {
+				frequencies[j].Count++
+				continue
+			}
+			index[key] = len(frequencies)
+			frequencies = append(frequencies, model.ConstraintFrequency{Constraint: constraint, Kind: key[1], Count: 1})
+		}
+	}
+	return frequencies
+}
@@ -3,6 +3,11 @@ package database
 import "GoParser/model"
 
 type genericsDatabase interface {
-	AddGenericCountersEntry(repository string, data model.GenericCounters)
+	AddRepositoryResult(repository, ref, commit string, result model.AnalysisResult, frequencies []model.ConstraintFrequency) error
+	GoVersionAdoption() ([]model.GoVersionAdoption, error)
+	AnalyzedRepositories() (map[string]string, error)
+	AddFailedRepository(repository string, reason string, attempts int) error
+	AddHistoryEntry(repository string, commit model.Commit, goDirectives model.GoDirectives, data model.GenericCounters) error
+	HistoryCommits(repository string) (map[string]bool, error)
 	Close() error
 }
@@ -3,27 +3,38 @@ package database
 import (
 	"GoParser/model"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"os"
 	"reflect"
 	"strings"
+	"sync"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// SQLiteDB is safe for concurrent use; all writes are serialized by mu.
+// columns are the columns of generic_counters, counterColumns only the counter columns
+// that are shared by all tables with counters.
 type SQLiteDB struct {
 	databaseObject *sql.DB
 	columns        []string
+	counterColumns []string
+	mu             sync.Mutex
 }
 
-func NewSQLiteDB(dbPath string, columns []string) (*SQLiteDB, error) {
+// NewSQLiteDB opens the database at dbPath or creates it if it does not exist.
+// Existing results are kept so that interrupted runs can be continued; only if
+// fresh is set an existing database file is deleted first.
+func NewSQLiteDB(dbPath string, columns []string, fresh bool) (*SQLiteDB, error) {
 	// Check if it is a database file
 	if !strings.HasSuffix(dbPath, ".db") {
 		return nil, fmt.Errorf("database file must have .db extension")
 	}
 
-	// Delete database file if it exists
-	if _, err := os.Stat(dbPath); err == nil {
+	// Delete database file if it exists and a fresh database is requested
+	if _, err := os.Stat(dbPath); err == nil && fresh {
 		if err := os.Remove(dbPath); err != nil {
 			return nil, err
 		}
@@ -35,29 +46,191 @@ func NewSQLiteDB(dbPath string, columns []string) (*SQLiteDB, error) {
 		return nil, err
 	}
 
-	sqliteDB := &SQLiteDB{databaseObject: db, columns: columns}
+	sqliteDB := &SQLiteDB{databaseObject: db, columns: columns, counterColumns: columns}
 
 	if err := sqliteDB.createGenericCountersTable("generic_counters"); err != nil {
 		db.Close()
 		return nil, err
 	}
 
+	if err := sqliteDB.createGenericDeclarationsTable(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if err := sqliteDB.createFailedRepositoriesTable(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if err := sqliteDB.createHistoryTable(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if err := sqliteDB.createConstraintFrequenciesTable(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if err := sqliteDB.createTypeParamStatsTable(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if err := sqliteDB.createGenericCandidatesTable(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if err := sqliteDB.createGenericAPIUsageTables(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if err := sqliteDB.createCategoryCountersTable(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if err := sqliteDB.createModuleCountersTable(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	return sqliteDB, nil
 }
 
 func (db *SQLiteDB) createGenericCountersTable(tableName string) error {
 	primaryKey := "repository"
-	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s STRING PRIMARY KEY, %s)",
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s STRING PRIMARY KEY, ref STRING, commit_sha STRING, module_path STRING, go_version STRING, toolchain STRING, %s)",
 		tableName, primaryKey, strings.Join(db.columns, ", "))
 
-	// Append primaryKey as primary key column
-	db.columns = append([]string{primaryKey}, db.columns...)
+	// Prepend primaryKey, the analysed revision and the Go version it declares, so every row can be traced back to its source
+	db.columns = append([]string{primaryKey, "ref", "commit_sha", "module_path", "go_version", "toolchain"}, db.columns...)
 
-	_, err := db.databaseObject.Exec(query)
+	if _, err := db.databaseObject.Exec(query); err != nil {
+		return err
+	}
+
+	return db.addMissingColumns(tableName, db.columns)
+}
+
+// addMissingColumns adds columns for counters that did not exist yet when the table
+// was created by an older version, so results of earlier runs stay usable.
+func (db *SQLiteDB) addMissingColumns(tableName string, columns []string) error {
+	rows, err := db.databaseObject.Query(fmt.Sprintf("PRAGMA table_info(%s)", tableName))
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var cid, notNull, primaryKey int
+		var name, columnType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &primaryKey); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, col := range columns {
+		if existing[col] {
+			continue
+		}
+		if _, err := db.databaseObject.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", tableName, col)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createGenericDeclarationsTable creates the table for the per-declaration detail records.
+// Every record references its row in generic_counters over the repository.
+func (db *SQLiteDB) createGenericDeclarationsTable() error {
+	query := `CREATE TABLE IF NOT EXISTS generic_declarations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		repository STRING NOT NULL REFERENCES generic_counters(repository),
+		file STRING,
+		line INTEGER,
+		name STRING,
+		kind STRING,
+		type_params STRING,
+		constraints STRING,
+		constraint_kinds STRING,
+		type_param_usages STRING,
+		non_trivial BOOLEAN
+	)`
+	if _, err := db.databaseObject.Exec(query); err != nil {
+		return err
+	}
+	if err := db.addMissingColumns("generic_declarations", []string{"constraint_kinds", "type_param_usages"}); err != nil {
+		return err
+	}
+
+	_, err := db.databaseObject.Exec("CREATE INDEX IF NOT EXISTS idx_generic_declarations_repository ON generic_declarations (repository)")
 	return err
 }
 
-func (db *SQLiteDB) AddGenericCountersEntry(repository string, data model.GenericCounters) error {
+// AddRepositoryResult stores the complete result of a repository in one transaction: the counters in
+// generic_counters and all detail tables. -resume treats a repository with a row in generic_counters as
+// done, so a run that is interrupted while writing must not leave a repository with missing details.
+// A previous failure of the repository is removed in the same transaction.
+func (db *SQLiteDB) AddRepositoryResult(repository, ref, commit string, result model.AnalysisResult, frequencies []model.ConstraintFrequency) error {
+	return db.inTransaction(func(tx *sql.Tx) error {
+		if err := db.addCategoryCounters(tx, repository, result.ByCategory); err != nil {
+			return err
+		}
+		if err := db.addModuleCounters(tx, repository, result.ByModule); err != nil {
+			return err
+		}
+		if err := db.addGenericDeclarations(tx, repository, result.Declarations); err != nil {
+			return err
+		}
+		if err := db.addConstraintFrequencies(tx, repository, frequencies); err != nil {
+			return err
+		}
+		if err := db.addTypeParamStats(tx, repository, result.TypeParams); err != nil {
+			return err
+		}
+		if err := db.addGenericCandidates(tx, repository, result.Candidates); err != nil {
+			return err
+		}
+		if err := db.addGenericAPIUsage(tx, repository, result.GenericAPIs); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM failed_repositories WHERE repository = ?", repository); err != nil {
+			return err
+		}
+		return db.addGenericCountersEntry(tx, repository, ref, commit, result.Go, result.Counters)
+	})
+}
+
+// inTransaction runs write in one transaction and commits it only if write succeeds
+func (db *SQLiteDB) inTransaction(write func(tx *sql.Tx) error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx, err := db.databaseObject.Begin()
+	if err != nil {
+		return err
+	}
+	if err := write(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// addGenericCountersEntry stores the counters of a repository together with the requested ref
+// (empty for the default branch), the SHA of the commit that was actually analysed and its Go directives.
+func (db *SQLiteDB) addGenericCountersEntry(tx *sql.Tx, repository, ref, commit string, goDirectives model.GoDirectives, data model.GenericCounters) error {
 	placeholders := make([]string, len(db.columns))
 	for i := range placeholders {
 		placeholders[i] = "?"
@@ -65,36 +238,530 @@ func (db *SQLiteDB) AddGenericCountersEntry(repository string, data model.Generi
 	values := make([]interface{}, len(db.columns))
 
 	for i, col := range db.columns {
-		if col == "repository" {
+		switch col {
+		case "repository":
 			values[i] = repository
 			continue
+		case "ref":
+			values[i] = ref
+			continue
+		case "commit_sha":
+			values[i] = commit
+			continue
+		case "module_path":
+			values[i] = goDirectives.ModulePath
+			continue
+		case "go_version":
+			values[i] = goDirectives.GoVersion
+			continue
+		case "toolchain":
+			values[i] = goDirectives.Toolchain
+			continue
 		}
-		v := reflect.ValueOf(data)
-		t := reflect.TypeOf(data)
-		colFound := false
-		for j := 0; j < t.NumField(); j++ {
-			jsonTag := t.Field(j).Tag.Get("json")
-			if jsonTag == col {
-				values[i] = v.Field(j).Interface()
-				colFound = true
-				break
-			}
+		value, err := counterValue(data, col)
+		if err != nil {
+			return err
 		}
-		if !colFound {
-			return fmt.Errorf("column %s not found in GenericCounters struct", col)
+		values[i] = value
+	}
+
+	// Upsert: a repository analysed again replaces its previous result
+	updates := make([]string, 0, len(db.columns))
+	for _, col := range db.columns {
+		if col != "repository" {
+			updates = append(updates, fmt.Sprintf("%s = excluded.%s", col, col))
 		}
 	}
 
 	query := fmt.Sprintf(
-		"INSERT INTO generic_counters (%s) VALUES (%s)",
+		"INSERT INTO generic_counters (%s) VALUES (%s) ON CONFLICT(repository) DO UPDATE SET %s",
 		strings.Join(db.columns, ", "),
 		strings.Join(placeholders, ", "),
+		strings.Join(updates, ", "),
 	)
 
+	_, err := tx.Exec(query, values...)
+	return err
+}
+
+// counterValue returns the value of the counter whose json tag is col
+func counterValue(data model.GenericCounters, col string) (interface{}, error) {
+	v := reflect.ValueOf(data)
+	t := reflect.TypeOf(data)
+	for j := 0; j < t.NumField(); j++ {
+		if t.Field(j).Tag.Get("json") == col {
+			return v.Field(j).Interface(), nil
+		}
+	}
+	return nil, fmt.Errorf("column %s not found in GenericCounters struct", col)
+}
+
+// addGenericDeclarations stores the detail records of a repository,
+// replacing records of a previous analysis of the same repository.
+// Type parameters and constraints are stored as JSON arrays, so they can be queried with json_each.
+func (db *SQLiteDB) addGenericDeclarations(tx *sql.Tx, repository string, declarations []model.GenericDeclaration) error {
+	if _, err := tx.Exec("DELETE FROM generic_declarations WHERE repository = ?", repository); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO generic_declarations
+		(repository, file, line, name, kind, type_params, constraints, constraint_kinds, type_param_usages, non_trivial)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, declaration := range declarations {
+		typeParams, err := json.Marshal(declaration.TypeParams)
+		if err != nil {
+			return err
+		}
+		constraints, err := json.Marshal(declaration.Constraints)
+		if err != nil {
+			return err
+		}
+		constraintKinds, err := json.Marshal(declaration.ConstraintKinds)
+		if err != nil {
+			return err
+		}
+		typeParamUsages, err := json.Marshal(declaration.TypeParamUsages)
+		if err != nil {
+			return err
+		}
+
+		if _, err := stmt.Exec(repository, declaration.File, declaration.Line, declaration.Name, declaration.Kind,
+			string(typeParams), string(constraints), string(constraintKinds), string(typeParamUsages), declaration.NonTrivial); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createConstraintFrequenciesTable creates the table that counts how often each constraint text is used per repository
+func (db *SQLiteDB) createConstraintFrequenciesTable() error {
+	query := `CREATE TABLE IF NOT EXISTS constraint_frequencies (
+		repository STRING NOT NULL REFERENCES generic_counters(repository),
+		constraint_text STRING NOT NULL,
+		kind STRING NOT NULL,
+		count INTEGER,
+		PRIMARY KEY (repository, constraint_text, kind)
+	)`
+	_, err := db.databaseObject.Exec(query)
+	return err
+}
+
+// addConstraintFrequencies stores the constraint frequencies of a repository,
+// replacing the frequencies of a previous analysis of the same repository.
+func (db *SQLiteDB) addConstraintFrequencies(tx *sql.Tx, repository string, frequencies []model.ConstraintFrequency) error {
+	if _, err := tx.Exec("DELETE FROM constraint_frequencies WHERE repository = ?", repository); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO constraint_frequencies (repository, constraint_text, kind, count) VALUES (?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, frequency := range frequencies {
+		if _, err := stmt.Exec(repository, frequency.Constraint, frequency.Kind, frequency.Count); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createTypeParamStatsTable creates the table for the type parameter histograms of every repository.
+// histogram is "arity" or "usage", bucket is one of model.TypeParamArities or model.TypeParamUsages.
+func (db *SQLiteDB) createTypeParamStatsTable() error {
+	query := `CREATE TABLE IF NOT EXISTS type_param_stats (
+		repository STRING NOT NULL REFERENCES generic_counters(repository),
+		histogram STRING NOT NULL,
+		bucket STRING NOT NULL,
+		count INTEGER,
+		PRIMARY KEY (repository, histogram, bucket)
+	)`
+	_, err := db.databaseObject.Exec(query)
+	return err
+}
+
+// addTypeParamStats stores the type parameter histograms of a repository,
+// replacing the histograms of a previous analysis. Empty buckets are stored with count 0.
+func (db *SQLiteDB) addTypeParamStats(tx *sql.Tx, repository string, stats model.TypeParamStats) error {
+	if _, err := tx.Exec("DELETE FROM type_param_stats WHERE repository = ?", repository); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO type_param_stats (repository, histogram, bucket, count) VALUES (?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, histogram := range []struct {
+		name    string
+		buckets []string
+		counts  map[string]int
+	}{
+		{"arity", model.TypeParamArities, stats.Arity},
+		{"usage", model.TypeParamUsages, stats.Usage},
+	} {
+		for _, bucket := range histogram.buckets {
+			if _, err := stmt.Exec(repository, histogram.name, bucket, histogram.counts[bucket]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// createGenericCandidatesTable creates the table for code that could use generics but does not
+func (db *SQLiteDB) createGenericCandidatesTable() error {
+	query := `CREATE TABLE IF NOT EXISTS generic_candidates (
+		repository STRING NOT NULL REFERENCES generic_counters(repository),
+		file STRING,
+		line INTEGER,
+		name STRING,
+		kind STRING,
+		detail STRING
+	)`
+	if _, err := db.databaseObject.Exec(query); err != nil {
+		return err
+	}
+
+	_, err := db.databaseObject.Exec("CREATE INDEX IF NOT EXISTS idx_generic_candidates_repository ON generic_candidates (repository)")
+	return err
+}
+
+// addGenericCandidates stores the candidates of a repository,
+// replacing the candidates of a previous analysis of the same repository.
+func (db *SQLiteDB) addGenericCandidates(tx *sql.Tx, repository string, candidates []model.GenericCandidate) error {
+	if _, err := tx.Exec("DELETE FROM generic_candidates WHERE repository = ?", repository); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO generic_candidates (repository, file, line, name, kind, detail) VALUES (?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, candidate := range candidates {
+		if _, err := stmt.Exec(repository, candidate.File, candidate.Line, candidate.Name, candidate.Kind, candidate.Detail); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createGenericAPIUsageTables creates the tables for the usage of generic standard library and x/exp
+// APIs: one row per imported package and one row per used identifier
+func (db *SQLiteDB) createGenericAPIUsageTables() error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS generic_api_packages (
+			repository STRING NOT NULL REFERENCES generic_counters(repository),
+			package STRING NOT NULL,
+			files INTEGER,
+			uses INTEGER,
+			PRIMARY KEY (repository, package)
+		)`,
+		`CREATE TABLE IF NOT EXISTS generic_api_symbols (
+			repository STRING NOT NULL REFERENCES generic_counters(repository),
+			package STRING NOT NULL,
+			symbol STRING NOT NULL,
+			uses INTEGER,
+			PRIMARY KEY (repository, package, symbol)
+		)`,
+	}
+	for _, query := range queries {
+		if _, err := db.databaseObject.Exec(query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addGenericAPIUsage stores the generic API usage of a repository,
+// replacing the usage of a previous analysis of the same repository.
+func (db *SQLiteDB) addGenericAPIUsage(tx *sql.Tx, repository string, usage model.GenericAPIUsage) error {
+	for _, table := range []string{"generic_api_packages", "generic_api_symbols"} {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE repository = ?", repository); err != nil {
+			return err
+		}
+	}
+
+	for importPath, files := range usage.Imports {
+		uses := 0
+		for _, count := range usage.Uses[importPath] {
+			uses += count
+		}
+		if _, err := tx.Exec("INSERT INTO generic_api_packages (repository, package, files, uses) VALUES (?, ?, ?, ?)",
+			repository, importPath, files, uses); err != nil {
+			return err
+		}
+	}
+	for importPath, symbols := range usage.Uses {
+		for symbol, count := range symbols {
+			if _, err := tx.Exec("INSERT INTO generic_api_symbols (repository, package, symbol, uses) VALUES (?, ?, ?, ?)",
+				repository, importPath, symbol, count); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// createCategoryCountersTable creates the table with the counters of every file category
+// (production, test, generated, vendored, example) per repository
+func (db *SQLiteDB) createCategoryCountersTable() error {
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS generic_counters_by_category (
+		repository STRING NOT NULL REFERENCES generic_counters(repository),
+		category STRING NOT NULL,
+		%s,
+		PRIMARY KEY (repository, category)
+	)`, strings.Join(db.counterColumns, ", "))
+	if _, err := db.databaseObject.Exec(query); err != nil {
+		return err
+	}
+
+	return db.addMissingColumns("generic_counters_by_category", db.counterColumns)
+}
+
+// addCategoryCounters stores the counters of every file category of a repository.
+// Categories without files are stored with all counters 0, so every repository has a row per category.
+func (db *SQLiteDB) addCategoryCounters(tx *sql.Tx, repository string, byCategory map[string]model.GenericCounters) error {
+	columns := append([]string{"repository", "category"}, db.counterColumns...)
+	placeholders := make([]string, len(columns))
+	for i := range placeholders {
+		placeholders[i] = "?"
+	}
+
+	stmt, err := tx.Prepare(fmt.Sprintf("INSERT OR REPLACE INTO generic_counters_by_category (%s) VALUES (%s)",
+		strings.Join(columns, ", "), strings.Join(placeholders, ", ")))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, category := range model.FileCategories {
+		values := []interface{}{repository, category}
+		for _, col := range db.counterColumns {
+			value, err := counterValue(byCategory[category], col)
+			if err != nil {
+				return err
+			}
+			values = append(values, value)
+		}
+		if _, err := stmt.Exec(values...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createModuleCountersTable creates the table with the counters of every module (go.mod) of a
+// repository. The row of the repository in generic_counters is the rollup over all its modules.
+func (db *SQLiteDB) createModuleCountersTable() error {
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS generic_counters_by_module (
+		repository STRING NOT NULL REFERENCES generic_counters(repository),
+		module_dir STRING NOT NULL,
+		module_path STRING,
+		go_version STRING,
+		toolchain STRING,
+		%s,
+		PRIMARY KEY (repository, module_dir)
+	)`, strings.Join(db.counterColumns, ", "))
+	if _, err := db.databaseObject.Exec(query); err != nil {
+		return err
+	}
+
+	return db.addMissingColumns("generic_counters_by_module", append([]string{"toolchain"}, db.counterColumns...))
+}
+
+// addModuleCounters replaces the module counters of a repository.
+// Packages outside of every module are stored with an empty module_dir.
+func (db *SQLiteDB) addModuleCounters(tx *sql.Tx, repository string, byModule map[string]model.ModuleCounters) error {
+	columns := append([]string{"repository", "module_dir", "module_path", "go_version", "toolchain"}, db.counterColumns...)
+	placeholders := make([]string, len(columns))
+	for i := range placeholders {
+		placeholders[i] = "?"
+	}
+
+	// Module einer früheren Analyse entfernen, die es im aktuellen Stand nicht mehr gibt
+	if _, err := tx.Exec("DELETE FROM generic_counters_by_module WHERE repository = ?", repository); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(fmt.Sprintf("INSERT INTO generic_counters_by_module (%s) VALUES (%s)",
+		strings.Join(columns, ", "), strings.Join(placeholders, ", ")))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, module := range byModule {
+		values := []interface{}{repository, module.Dir, module.ModulePath, module.GoVersion, module.Toolchain}
+		for _, col := range db.counterColumns {
+			value, err := counterValue(module.Counters, col)
+			if err != nil {
+				return err
+			}
+			values = append(values, value)
+		}
+		if _, err := stmt.Exec(values...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GoVersionAdoption counts the modules per declared go directive that declare or use generics.
+// Packages outside every module (empty module_dir) are left out, they have no go directive.
+// The rows are grouped by the directive as written, e.g. "1.21" and "1.21.0" are separate rows.
+func (db *SQLiteDB) GoVersionAdoption() ([]model.GoVersionAdoption, error) {
+	rows, err := db.databaseObject.Query(`SELECT COALESCE(go_version, ''),
+		COUNT(*),
+		SUM(func_generic > 0 OR generic_type_decl > 0),
+		SUM(instantiation_explicit > 0 OR instantiation_inferred > 0 OR generic_api_uses > 0)
+		FROM generic_counters_by_module
+		WHERE module_dir != ''
+		GROUP BY COALESCE(go_version, '')
+		ORDER BY 1`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var adoption []model.GoVersionAdoption
+	for rows.Next() {
+		var a model.GoVersionAdoption
+		if err := rows.Scan(&a.GoVersion, &a.Modules, &a.Declaring, &a.Using); err != nil {
+			return nil, err
+		}
+		adoption = append(adoption, a)
+	}
+	return adoption, rows.Err()
+}
+
+// createFailedRepositoriesTable creates the table for repositories that could not be downloaded,
+// so that they are not silently missing from the dataset.
+func (db *SQLiteDB) createFailedRepositoriesTable() error {
+	query := `CREATE TABLE IF NOT EXISTS failed_repositories (
+		repository STRING PRIMARY KEY,
+		reason STRING,
+		attempts INTEGER,
+		failed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`
+	_, err := db.databaseObject.Exec(query)
+	return err
+}
+
+// AddFailedRepository records a repository whose download finally failed, replacing an earlier failure
+func (db *SQLiteDB) AddFailedRepository(repository string, reason string, attempts int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	_, err := db.databaseObject.Exec(`INSERT INTO failed_repositories (repository, reason, attempts, failed_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(repository) DO UPDATE SET reason = excluded.reason, attempts = excluded.attempts, failed_at = excluded.failed_at`,
+		repository, reason, attempts)
+	return err
+}
+
+// AnalyzedRepositories returns all repositories that already have a result in the database,
+// mapped to the ref they were analysed at
+func (db *SQLiteDB) AnalyzedRepositories() (map[string]string, error) {
+	rows, err := db.databaseObject.Query("SELECT repository, COALESCE(ref, '') FROM generic_counters")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	repositories := make(map[string]string)
+	for rows.Next() {
+		var repository, ref string
+		if err := rows.Scan(&repository, &ref); err != nil {
+			return nil, err
+		}
+		repositories[repository] = ref
+	}
+	return repositories, rows.Err()
+}
+
+// createHistoryTable creates the table for the time series of a repository.
+// Every row holds the counters of one sampled commit, identified by repository and commit SHA.
+func (db *SQLiteDB) createHistoryTable() error {
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS history (
+		repository STRING NOT NULL,
+		commit_sha STRING NOT NULL,
+		commit_date DATETIME,
+		tag STRING,
+		go_version STRING,
+		toolchain STRING,
+		%s,
+		PRIMARY KEY (repository, commit_sha)
+	)`, strings.Join(db.counterColumns, ", "))
+	if _, err := db.databaseObject.Exec(query); err != nil {
+		return err
+	}
+
+	return db.addMissingColumns("history", append([]string{"go_version", "toolchain"}, db.counterColumns...))
+}
+
+// AddHistoryEntry stores the counters of one commit of a repository's history together with the
+// Go version declared at that commit, replacing the result of an earlier analysis of the same commit.
+func (db *SQLiteDB) AddHistoryEntry(repository string, commit model.Commit, goDirectives model.GoDirectives, data model.GenericCounters) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	columns := append([]string{"repository", "commit_sha", "commit_date", "tag", "go_version", "toolchain"}, db.counterColumns...)
+	values := []interface{}{repository, commit.SHA, commit.Date.UTC().Format(time.RFC3339), commit.Tag, goDirectives.GoVersion, goDirectives.Toolchain}
+	for _, col := range db.counterColumns {
+		value, err := counterValue(data, col)
+		if err != nil {
+			return err
+		}
+		values = append(values, value)
+	}
+
+	placeholders := make([]string, len(columns))
+	for i := range placeholders {
+		placeholders[i] = "?"
+	}
+
+	query := fmt.Sprintf("INSERT OR REPLACE INTO history (%s) VALUES (%s)",
+		strings.Join(columns, ", "), strings.Join(placeholders, ", "))
 	_, err := db.databaseObject.Exec(query, values...)
 	return err
 }
 
+// HistoryCommits returns the SHAs of all commits of a repository that are already part of its history
+func (db *SQLiteDB) HistoryCommits(repository string) (map[string]bool, error) {
+	rows, err := db.databaseObject.Query("SELECT commit_sha FROM history WHERE repository = ?", repository)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	commits := make(map[string]bool)
+	for rows.Next() {
+		var sha string
+		if err := rows.Scan(&sha); err != nil {
+			return nil, err
+		}
+		commits[sha] = true
+	}
+	return commits, rows.Err()
+}
+
 func (db *SQLiteDB) Close() error {
 	if db.databaseObject != nil {
 		return db.databaseObject.Close()
@@ -4,16 +4,17 @@ import (
 	"GoParser/model"
 	"reflect"
 	"testing"
+	"time"
 )
 
-func TestAddGenericCountersEntries(t *testing.T) {
+func TestAddRepositoryResultCounters(t *testing.T) {
 	var columns []string
 	typeOfCounters := reflect.TypeOf(model.GenericCounters{})
 	for i := 0; i < typeOfCounters.NumField(); i++ {
 		columns = append(columns, typeOfCounters.Field(i).Tag.Get("json"))
 	}
 
-	db, err := NewSQLiteDB("test.db", columns)
+	db, err := NewSQLiteDB("test.db", columns, true)
 	if err != nil {
 		t.Fatalf("failed to create db: %v", err)
 	}
@@ -38,10 +39,350 @@ func TestAddGenericCountersEntries(t *testing.T) {
 	}
 	entry2 := model.GenericCounters{}
 
-	if err := db.AddGenericCountersEntry("repo1", entry1); err != nil {
+	if err := db.AddRepositoryResult("repo1", "", "abc123", model.AnalysisResult{Counters: entry1}, nil); err != nil {
 		t.Errorf("failed to add entry1: %v", err)
 	}
-	if err := db.AddGenericCountersEntry("repo2", entry2); err != nil {
+	if err := db.AddRepositoryResult("repo2", "v1.0", "def456", model.AnalysisResult{Counters: entry2}, nil); err != nil {
 		t.Errorf("failed to add entry2: %v", err)
 	}
 }
+
+func TestAddRepositoryResultDeclarations(t *testing.T) {
+	db, err := NewSQLiteDB("test_declarations.db", []string{"func_total"}, true)
+	if err != nil {
+		t.Fatalf("failed to create db: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("failed to close db: %v", err)
+		}
+	}()
+
+	declarations := []model.GenericDeclaration{
+		{File: "a.go", Line: 3, Name: "Map", Kind: model.DeclarationKindFunc, TypeParams: []string{"K", "V"}, Constraints: []string{"comparable", "any"}, NonTrivial: true},
+		{File: "b.go", Line: 7, Name: "List", Kind: model.DeclarationKindStruct, TypeParams: []string{"T"}, Constraints: []string{"any"}},
+	}
+	result := model.AnalysisResult{Counters: model.GenericCounters{FuncTotal: 1}, Declarations: declarations}
+	if err := db.AddRepositoryResult("repo1", "", "", result, nil); err != nil {
+		t.Fatalf("failed to add declarations: %v", err)
+	}
+
+	var count int
+	row := db.databaseObject.QueryRow(`SELECT COUNT(*) FROM generic_declarations d, json_each(d.constraints) c
+		WHERE d.repository = 'repo1' AND c.value = 'any'`)
+	if err := row.Scan(&count); err != nil {
+		t.Fatalf("failed to query declarations: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("declarations with an any constraint = %d, want 2", count)
+	}
+}
+
+func TestReopenKeepsAndUpdatesEntries(t *testing.T) {
+	db, err := NewSQLiteDB("test_resume.db", []string{"func_total"}, true)
+	if err != nil {
+		t.Fatalf("failed to create db: %v", err)
+	}
+	if err := db.AddRepositoryResult("repo1", "", "", model.AnalysisResult{Counters: model.GenericCounters{FuncTotal: 1}}, nil); err != nil {
+		t.Fatalf("failed to add entry: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("failed to close db: %v", err)
+	}
+
+	// Reopen with an additional column, as after adding a new counter
+	db, err = NewSQLiteDB("test_resume.db", []string{"func_total", "func_generic"}, false)
+	if err != nil {
+		t.Fatalf("failed to reopen db: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("failed to close db: %v", err)
+		}
+	}()
+
+	repositories, err := db.AnalyzedRepositories()
+	if err != nil {
+		t.Fatalf("failed to read repositories: %v", err)
+	}
+	if _, ok := repositories["repo1"]; !ok {
+		t.Errorf("repo1 was not kept after reopening the database")
+	}
+
+	// Analysing the same repository again must not fail on the primary key
+	result := model.AnalysisResult{
+		Counters: model.GenericCounters{FuncTotal: 2, FuncGeneric: 1},
+		Go:       model.GoDirectives{ModulePath: "example.com/repo1", GoVersion: "1.22.0", Toolchain: "go1.22.3"},
+	}
+	if err := db.AddRepositoryResult("repo1", "v2", "def456", result, nil); err != nil {
+		t.Fatalf("failed to update entry: %v", err)
+	}
+
+	var funcTotal, funcGeneric int
+	var ref, commit string
+	row := db.databaseObject.QueryRow("SELECT func_total, func_generic, ref, commit_sha FROM generic_counters WHERE repository = 'repo1'")
+	if err := row.Scan(&funcTotal, &funcGeneric, &ref, &commit); err != nil {
+		t.Fatalf("failed to query entry: %v", err)
+	}
+	if funcTotal != 2 || funcGeneric != 1 {
+		t.Errorf("got func_total=%d func_generic=%d, want 2 and 1", funcTotal, funcGeneric)
+	}
+	if ref != "v2" || commit != "def456" {
+		t.Errorf("got ref=%q commit_sha=%q, want v2 and def456", ref, commit)
+	}
+
+	var goVersion, toolchain string
+	row = db.databaseObject.QueryRow("SELECT go_version, toolchain FROM generic_counters WHERE repository = 'repo1'")
+	if err := row.Scan(&goVersion, &toolchain); err != nil {
+		t.Fatalf("failed to query go directives: %v", err)
+	}
+	if goVersion != "1.22.0" || toolchain != "go1.22.3" {
+		t.Errorf("got go_version=%q toolchain=%q, want 1.22.0 and go1.22.3", goVersion, toolchain)
+	}
+}
+
+func TestFailedRepositories(t *testing.T) {
+	db, err := NewSQLiteDB("test_failures.db", []string{"func_total"}, true)
+	if err != nil {
+		t.Fatalf("failed to create db: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("failed to close db: %v", err)
+		}
+	}()
+
+	if err := db.AddFailedRepository("owner/repo", "502 Bad Gateway", 3); err != nil {
+		t.Fatalf("failed to add failure: %v", err)
+	}
+	// A second failure of the same repository replaces the first one
+	if err := db.AddFailedRepository("owner/repo", "404 Not Found", 1); err != nil {
+		t.Fatalf("failed to update failure: %v", err)
+	}
+
+	var reason string
+	var attempts int
+	row := db.databaseObject.QueryRow("SELECT reason, attempts FROM failed_repositories WHERE repository = 'owner/repo'")
+	if err := row.Scan(&reason, &attempts); err != nil {
+		t.Fatalf("failed to query failure: %v", err)
+	}
+	if reason != "404 Not Found" || attempts != 1 {
+		t.Errorf("got reason=%q attempts=%d, want the latest failure", reason, attempts)
+	}
+
+	// A successful analysis removes the failure
+	if err := db.AddRepositoryResult("owner/repo", "", "", model.AnalysisResult{}, nil); err != nil {
+		t.Fatalf("failed to add result: %v", err)
+	}
+	var count int
+	if err := db.databaseObject.QueryRow("SELECT COUNT(*) FROM failed_repositories").Scan(&count); err != nil {
+		t.Fatalf("failed to count failures: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("got %d failures after removal, want 0", count)
+	}
+}
+
+func TestAddHistoryEntry(t *testing.T) {
+	db, err := NewSQLiteDB("test_history.db", []string{"func_total", "func_generic"}, true)
+	if err != nil {
+		t.Fatalf("failed to create db: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("failed to close db: %v", err)
+		}
+	}()
+
+	march := model.Commit{SHA: "abc123", Date: time.Date(2022, 3, 31, 12, 0, 0, 0, time.UTC)}
+	april := model.Commit{SHA: "def456", Date: time.Date(2022, 4, 30, 12, 0, 0, 0, time.UTC), Tag: "v1.0"}
+	if err := db.AddHistoryEntry("local/project", march, model.GoDirectives{}, model.GenericCounters{FuncTotal: 10}); err != nil {
+		t.Fatalf("failed to add march: %v", err)
+	}
+	if err := db.AddHistoryEntry("local/project", april, model.GoDirectives{}, model.GenericCounters{FuncTotal: 12, FuncGeneric: 1}); err != nil {
+		t.Fatalf("failed to add april: %v", err)
+	}
+	// Analysing the same commit again replaces its row
+	if err := db.AddHistoryEntry("local/project", april, model.GoDirectives{}, model.GenericCounters{FuncTotal: 12, FuncGeneric: 2}); err != nil {
+		t.Fatalf("failed to replace april: %v", err)
+	}
+
+	commits, err := db.HistoryCommits("local/project")
+	if err != nil {
+		t.Fatalf("failed to read history commits: %v", err)
+	}
+	if len(commits) != 2 || !commits["abc123"] || !commits["def456"] {
+		t.Errorf("got history commits %v, want abc123 and def456", commits)
+	}
+
+	var funcGeneric int
+	var tag string
+	row := db.databaseObject.QueryRow("SELECT func_generic, tag FROM history ORDER BY commit_date DESC LIMIT 1")
+	if err := row.Scan(&funcGeneric, &tag); err != nil {
+		t.Fatalf("failed to query history: %v", err)
+	}
+	if funcGeneric != 2 || tag != "v1.0" {
+		t.Errorf("got func_generic=%d tag=%q for the newest commit, want 2 and v1.0", funcGeneric, tag)
+	}
+}
+
+func TestAddRepositoryResultTypeParamStats(t *testing.T) {
+	db, err := NewSQLiteDB("test_type_param_stats.db", []string{"func_total"}, true)
+	if err != nil {
+		t.Fatalf("failed to create db: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("failed to close db: %v", err)
+		}
+	}()
+
+	stats := model.TypeParamStats{
+		Arity: map[string]int{model.TypeParamArity1: 4, model.TypeParamArity2: 1},
+		Usage: map[string]int{model.TypeParamUsageParams: 5, model.TypeParamUsageUnused: 1},
+	}
+	if err := db.AddRepositoryResult("repo1", "", "", model.AnalysisResult{TypeParams: stats}, nil); err != nil {
+		t.Fatalf("failed to add stats: %v", err)
+	}
+	// Eine erneute Analyse ersetzt die Histogramme
+	if err := db.AddRepositoryResult("repo1", "", "", model.AnalysisResult{TypeParams: stats}, nil); err != nil {
+		t.Fatalf("failed to replace stats: %v", err)
+	}
+
+	var rows, declarations int
+	row := db.databaseObject.QueryRow(`SELECT COUNT(*), SUM(count) FROM type_param_stats WHERE repository = 'repo1' AND histogram = 'arity'`)
+	if err := row.Scan(&rows, &declarations); err != nil {
+		t.Fatalf("failed to query stats: %v", err)
+	}
+	if rows != len(model.TypeParamArities) || declarations != 5 {
+		t.Errorf("arity histogram has %d buckets with %d declarations, want %d buckets with 5", rows, declarations, len(model.TypeParamArities))
+	}
+}
+
+func TestAddRepositoryResultModuleCounters(t *testing.T) {
+	db, err := NewSQLiteDB("test_module_counters.db", []string{"func_total", "func_generic"}, true)
+	if err != nil {
+		t.Fatalf("failed to create db: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("failed to close db: %v", err)
+		}
+	}()
+
+	byModule := map[string]model.ModuleCounters{
+		".":                      {Dir: ".", GoDirectives: model.GoDirectives{ModulePath: "k8s.io/kubernetes", GoVersion: "1.22.0"}, Counters: model.GenericCounters{FuncTotal: 10, FuncGeneric: 2}},
+		"staging/src/k8s.io/api": {Dir: "staging/src/k8s.io/api", GoDirectives: model.GoDirectives{ModulePath: "k8s.io/api", GoVersion: "1.21"}, Counters: model.GenericCounters{FuncTotal: 5, FuncGeneric: 1}},
+	}
+	if err := db.AddRepositoryResult("repo1", "", "", model.AnalysisResult{ByModule: byModule}, nil); err != nil {
+		t.Fatalf("failed to add module counters: %v", err)
+	}
+	// Eine erneute Analyse ersetzt die Module, auch wenn eines weggefallen ist
+	delete(byModule, "staging/src/k8s.io/api")
+	if err := db.AddRepositoryResult("repo1", "", "", model.AnalysisResult{ByModule: byModule}, nil); err != nil {
+		t.Fatalf("failed to replace module counters: %v", err)
+	}
+
+	var rows, funcGeneric int
+	var goVersion string
+	row := db.databaseObject.QueryRow(`SELECT COUNT(*), SUM(func_generic), MAX(go_version) FROM generic_counters_by_module WHERE repository = 'repo1'`)
+	if err := row.Scan(&rows, &funcGeneric, &goVersion); err != nil {
+		t.Fatalf("failed to query module counters: %v", err)
+	}
+	if rows != 1 || funcGeneric != 2 || goVersion != "1.22.0" {
+		t.Errorf("got %d modules with %d generic functions and go %s, want 1 module with 2 and go 1.22.0", rows, funcGeneric, goVersion)
+	}
+}
+
+func TestGoVersionAdoption(t *testing.T) {
+	columns := []string{"func_generic", "generic_type_decl", "instantiation_explicit", "instantiation_inferred", "generic_api_uses"}
+	db, err := NewSQLiteDB("test_go_versions.db", columns, true)
+	if err != nil {
+		t.Fatalf("failed to create db: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("failed to close db: %v", err)
+		}
+	}()
+
+	modules := map[string]map[string]model.ModuleCounters{
+		"old/repo": {".": {Dir: ".", GoDirectives: model.GoDirectives{GoVersion: "1.17"}}},
+		"new/repo": {
+			".":    {Dir: ".", GoDirectives: model.GoDirectives{GoVersion: "1.21"}, Counters: model.GenericCounters{FuncGeneric: 3}},
+			"tool": {Dir: "tool", GoDirectives: model.GoDirectives{GoVersion: "1.21"}, Counters: model.GenericCounters{GenericAPIUses: 1}},
+		},
+		// Pakete außerhalb jedes Moduls haben keine go-Direktive und zählen nicht mit
+		"gopath/repo": {"": {Counters: model.GenericCounters{FuncGeneric: 1}}},
+	}
+	for repository, byModule := range modules {
+		if err := db.AddRepositoryResult(repository, "", "", model.AnalysisResult{ByModule: byModule}, nil); err != nil {
+			t.Fatalf("failed to add module counters: %v", err)
+		}
+	}
+
+	adoption, err := db.GoVersionAdoption()
+	if err != nil {
+		t.Fatalf("failed to query adoption: %v", err)
+	}
+	want := []model.GoVersionAdoption{
+		{GoVersion: "1.17", Modules: 1},
+		{GoVersion: "1.21", Modules: 2, Declaring: 1, Using: 1},
+	}
+	if !reflect.DeepEqual(adoption, want) {
+		t.Errorf("got %+v, want %+v", adoption, want)
+	}
+}
+
+func TestAddRepositoryResultIsAtomic(t *testing.T) {
+	db, err := NewSQLiteDB("test_repository_result.db", []string{"func_total", "func_generic"}, true)
+	if err != nil {
+		t.Fatalf("failed to create db: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("failed to close db: %v", err)
+		}
+	}()
+
+	result := model.AnalysisResult{
+		Counters:     model.GenericCounters{FuncTotal: 2, FuncGeneric: 1},
+		ByModule:     map[string]model.ModuleCounters{".": {Dir: ".", Counters: model.GenericCounters{FuncTotal: 2, FuncGeneric: 1}}},
+		Declarations: []model.GenericDeclaration{{File: "a.go", Line: 3, Name: "Map", Kind: "func"}},
+		Candidates:   []model.GenericCandidate{{File: "a.go", Line: 10, Name: "MaxInt", Kind: "duplicate_func"}},
+	}
+	if err := db.AddFailedRepository("repo1", "timeout", 5); err != nil {
+		t.Fatalf("failed to add failed repository: %v", err)
+	}
+	if err := db.AddRepositoryResult("repo1", "", "abc123", result, nil); err != nil {
+		t.Fatalf("failed to add repository result: %v", err)
+	}
+
+	// Schlägt ein Detail-Schreibvorgang fehl, darf das Repository nicht als analysiert gelten
+	if _, err := db.databaseObject.Exec("DROP TABLE generic_candidates"); err != nil {
+		t.Fatalf("failed to drop table: %v", err)
+	}
+	if err := db.AddRepositoryResult("repo2", "", "def456", result, nil); err == nil {
+		t.Fatalf("expected error without generic_candidates table")
+	}
+
+	analyzed, err := db.AnalyzedRepositories()
+	if err != nil {
+		t.Fatalf("failed to read analysed repositories: %v", err)
+	}
+	if _, ok := analyzed["repo2"]; ok || len(analyzed) != 1 {
+		t.Errorf("got analysed repositories %v, want only repo1", analyzed)
+	}
+
+	var modules, declarations, failures int
+	row := db.databaseObject.QueryRow(`SELECT
+		(SELECT COUNT(*) FROM generic_counters_by_module WHERE repository = 'repo2'),
+		(SELECT COUNT(*) FROM generic_declarations WHERE repository = 'repo2'),
+		(SELECT COUNT(*) FROM failed_repositories)`)
+	if err := row.Scan(&modules, &declarations, &failures); err != nil {
+		t.Fatalf("failed to query details: %v", err)
+	}
+	if modules != 0 || declarations != 0 || failures != 0 {
+		t.Errorf("got %d modules, %d declarations and %d failures, want none", modules, declarations, failures)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"GoParser/model"
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// collectDeclarations creates a detail record for every generic function and type declaration of a file
+func collectDeclarations(fset *token.FileSet, file *ast.File, pkgInfo *packageInfo) []model.GenericDeclaration {
+	var declarations []model.GenericDeclaration
+	imports := importPaths(file)
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv != nil || d.Type.TypeParams == nil || len(d.Type.TypeParams.List) == 0 {
+				continue
+			}
+			declaration := newDeclaration(fset, d.Name, model.DeclarationKindFunc, d.Type.TypeParams, imports, pkgInfo)
+			// Funktionen ohne Rumpf (z.B. in Assembler implementiert) haben keinen Body
+			var body ast.Node
+			if d.Body != nil {
+				body = d.Body
+			}
+			declaration.TypeParamUsages = typeParamUsages(d.Type.TypeParams, d.Type.Params, d.Type.Results, body)
+			// Semantic classification overrides the syntactic one if the constraints could be resolved
+			if pkgInfo.typesInfo != nil {
+				if fn, ok := pkgInfo.typesInfo.Defs[d.Name].(*types.Func); ok {
+					if info, ok := classifyTypeParams(fn.Type().(*types.Signature).TypeParams()); ok {
+						declaration.NonTrivial = info.hasNonTrivialBound
+					}
+				}
+			}
+			declarations = append(declarations, declaration)
+
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				typeSpec, ok := spec.(*ast.TypeSpec)
+				if !ok || typeSpec.TypeParams == nil || len(typeSpec.TypeParams.List) == 0 {
+					continue
+				}
+				kind := model.DeclarationKindType
+				switch typeSpec.Type.(type) {
+				case *ast.StructType:
+					kind = model.DeclarationKindStruct
+				case *ast.InterfaceType:
+					kind = model.DeclarationKindInterface
+				}
+				if typeSpec.Assign.IsValid() {
+					kind = model.DeclarationKindAlias
+				}
+				declaration := newDeclaration(fset, typeSpec.Name, kind, typeSpec.TypeParams, imports, pkgInfo)
+				declaration.TypeParamUsages = typeParamUsages(typeSpec.TypeParams, nil, nil, typeSpec.Type)
+				// Type bounds of types are already classified by the first pass (syntactic or go/types)
+				if info, exists := pkgInfo.typeBoundsInfo[typeSpec.Name.Name]; exists {
+					declaration.NonTrivial = info.hasNonTrivialBound
+				}
+				declarations = append(declarations, declaration)
+			}
+		}
+	}
+
+	return declarations
+}
+
+func newDeclaration(fset *token.FileSet, name *ast.Ident, kind string, typeParams *ast.FieldList, imports map[string]string, pkgInfo *packageInfo) model.GenericDeclaration {
+	position := fset.Position(name.Pos())
+	declaration := model.GenericDeclaration{
+		File: position.Filename,
+		Line: position.Line,
+		Name: name.Name,
+		Kind: kind,
+	}
+
+	for _, field := range typeParams.List {
+		constraint := types.ExprString(field.Type)
+		for _, paramName := range field.Names {
+			declaration.TypeParams = append(declaration.TypeParams, paramName.Name)
+			declaration.Constraints = append(declaration.Constraints, constraint)
+			declaration.ConstraintKinds = append(declaration.ConstraintKinds, constraintKind(paramName, field.Type, imports, pkgInfo))
+		}
+		if isTrivial, _ := classifyConstraint(field.Type, pkgInfo.typeSpecs); !isTrivial {
+			declaration.NonTrivial = true
+		}
+	}
+
+	return declaration
+}
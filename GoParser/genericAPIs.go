@@ -0,0 +1,95 @@
+package main
+
+import (
+	"GoParser/model"
+	"go/ast"
+)
+
+// genericAPIs lists the packages of the standard library and golang.org/x/exp that provide
+// generic APIs. A nil set means that every exported identifier of the package is generic,
+// otherwise only the listed identifiers are.
+var genericAPIs = map[string]map[string]bool{
+	"cmp":                          nil,
+	"iter":                         nil,
+	"maps":                         nil,
+	"slices":                       nil,
+	"unique":                       nil,
+	"math/rand/v2":                 {"N": true},
+	"reflect":                      {"TypeFor": true},
+	"sync":                         {"OnceValue": true, "OnceValues": true},
+	"sync/atomic":                  {"Pointer": true},
+	"weak":                         nil,
+	"golang.org/x/exp/constraints": nil,
+	"golang.org/x/exp/maps":        nil,
+	"golang.org/x/exp/slices":      nil,
+}
+
+// countGenericAPIUsage counts the imports of generic API packages and every selector like
+// slices.Contains or atomic.Pointer that refers to a generic identifier of such a package.
+// Packages like reflect are only counted as import if a generic identifier is used.
+func countGenericAPIUsage(file *ast.File, usage *model.GenericAPIUsage, counters *model.GenericCounters) {
+	imports := importPaths(file)
+	uses := make(map[string]int)
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		selector, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		// Paketnamen werden vom Parser nicht aufgelöst (Obj == nil), lokale Variablen schon
+		pkg, ok := selector.X.(*ast.Ident)
+		if !ok || pkg.Obj != nil {
+			return true
+		}
+		importPath, ok := imports[pkg.Name]
+		if !ok {
+			return true
+		}
+		symbols, known := genericAPIs[importPath]
+		if !known || (symbols != nil && !symbols[selector.Sel.Name]) {
+			return true
+		}
+
+		if usage.Uses == nil {
+			usage.Uses = make(map[string]map[string]int)
+		}
+		if usage.Uses[importPath] == nil {
+			usage.Uses[importPath] = make(map[string]int)
+		}
+		usage.Uses[importPath][selector.Sel.Name]++
+		uses[importPath]++
+		counters.GenericAPIUses++
+		return true
+	})
+
+	for _, importPath := range imports {
+		symbols, known := genericAPIs[importPath]
+		if !known || (symbols != nil && uses[importPath] == 0) {
+			continue
+		}
+		if usage.Imports == nil {
+			usage.Imports = make(map[string]int)
+		}
+		usage.Imports[importPath]++
+		counters.GenericAPIImports++
+	}
+}
+
+// aggregateGenericAPIUsage adds the generic API usage of source to target
+func aggregateGenericAPIUsage(target *model.GenericAPIUsage, source model.GenericAPIUsage) {
+	if target.Imports == nil {
+		target.Imports = make(map[string]int)
+		target.Uses = make(map[string]map[string]int)
+	}
+	for importPath, files := range source.Imports {
+		target.Imports[importPath] += files
+	}
+	for importPath, symbols := range source.Uses {
+		if target.Uses[importPath] == nil {
+			target.Uses[importPath] = make(map[string]int)
+		}
+		for symbol, count := range symbols {
+			target.Uses[importPath][symbol] += count
+		}
+	}
+}
@@ -5,6 +5,7 @@ go 1.25.0
 require (
 	github.com/google/go-github/v60 v60.0.0
 	github.com/mattn/go-sqlite3 v1.14.32
+	golang.org/x/mod v0.40.0
 )
 
 require (
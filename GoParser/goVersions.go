@@ -0,0 +1,68 @@
+package main
+
+import (
+	"GoParser/database"
+	"GoParser/model"
+	"GoParser/utils"
+	"fmt"
+	"slices"
+)
+
+// goVersionsHeader enthält die Spalten von printGoVersionReport
+const goVersionsHeader = "GoVersion,GenericsAvailable,Modules,DeclaringGenerics,UsingGenerics"
+
+// printGoVersionReport gibt die Verbreitung von Generics je Sprachversion der go-Direktive aus.
+// So lassen sich Module, die Generics nicht verwenden können (go < 1.18), von denen trennen,
+// die sie verwenden könnten, aber keine deklarieren.
+func printGoVersionReport(sqliteDB *database.SQLiteDB) error {
+	rows, err := sqliteDB.GoVersionAdoption()
+	if err != nil {
+		return err
+	}
+	adoption := groupByLanguageVersion(rows)
+
+	fmt.Println(goVersionsHeader)
+	var unavailable, notDeclaring, declaring int
+	for _, a := range adoption {
+		available := utils.GenericsAvailable(a.GoVersion)
+		fmt.Printf("%s,%t,%d,%d,%d\n", a.GoVersion, available, a.Modules, a.Declaring, a.Using)
+
+		if !available {
+			unavailable += a.Modules
+			continue
+		}
+		declaring += a.Declaring
+		notDeclaring += a.Modules - a.Declaring
+	}
+
+	fmt.Println()
+	fmt.Println("Modules by go directive:")
+	fmt.Printf("CannotUseGenerics (go < 1.18 or no go directive): %v\n", unavailable)
+	fmt.Printf("CanUseGenericsButDeclareNone: %v\n", notDeclaring)
+	fmt.Printf("DeclareGenerics: %v\n", declaring)
+	return nil
+}
+
+// groupByLanguageVersion fasst go-Direktiven mit derselben Sprachversion zusammen (z.B. "1.21" und "1.21.0")
+// und sortiert sie numerisch, Module ohne go-Direktive zuerst
+func groupByLanguageVersion(rows []model.GoVersionAdoption) []model.GoVersionAdoption {
+	var grouped []model.GoVersionAdoption
+	index := make(map[string]int)
+	for _, row := range rows {
+		version := utils.GoLanguageVersion(row.GoVersion)
+		i, ok := index[version]
+		if !ok {
+			i = len(grouped)
+			index[version] = i
+			grouped = append(grouped, model.GoVersionAdoption{GoVersion: version})
+		}
+		grouped[i].Modules += row.Modules
+		grouped[i].Declaring += row.Declaring
+		grouped[i].Using += row.Using
+	}
+
+	slices.SortFunc(grouped, func(a, b model.GoVersionAdoption) int {
+		return utils.CompareGoLanguageVersions(a.GoVersion, b.GoVersion)
+	})
+	return grouped
+}
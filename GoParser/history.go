@@ -0,0 +1,90 @@
+package main
+
+import (
+	"GoParser/database"
+	"GoParser/model"
+	"GoParser/utils"
+	"fmt"
+	"log"
+	"time"
+)
+
+// analyzeHistory analysiert ausgewählte Stände eines lokalen Git-Repositories und speichert
+// die Zähler jedes Stands in der Tabelle history. Es werden nur lokale Daten aus .git gelesen.
+// Bei resume werden Commits übersprungen, die bereits in der Zeitreihe enthalten sind.
+func analyzeHistory(
+	sqliteDB *database.SQLiteDB,
+	projectPath, projectName, sampling string,
+	workers int,
+	resume bool,
+	newAnalyzer func() ASTAnalyzer,
+) error {
+	commits, err := utils.SelectHistoryCommits(projectPath, sampling)
+	if err != nil {
+		return err
+	}
+
+	analyzed := make(map[string]bool)
+	if resume {
+		analyzed, err = sqliteDB.HistoryCommits(projectName)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Die Commits werden wie Repositories über den Worker-Pool analysiert; die Ref ist der Commit-SHA
+	commitsBySHA := make(map[string]model.Commit)
+	var entries []utils.RepositoryEntry
+	for _, commit := range commits {
+		if analyzed[commit.SHA] {
+			continue
+		}
+		commitsBySHA[commit.SHA] = commit
+		entries = append(entries, utils.RepositoryEntry{Owner: "local", Repo: projectName, Ref: commit.SHA})
+	}
+	log.Printf("Analysing %d of %d sampled commits of %s", len(entries), len(commits), projectName)
+
+	fetch := func(entry utils.RepositoryEntry) (utils.SourceTree, string, error) {
+		tree, err := utils.FetchGitTree(projectPath, entry.Ref)
+		return tree, entry.Ref, err
+	}
+
+	fmt.Println("CommitDate,Commit,Tag,FuncTotal,FuncGeneric,MethodTotal,MethodWithGenericReceiver,StructTotal,StructGeneric,TypeDecl,GenericTypeDecl,GenericTypeSet,InstantiationExplicit")
+
+	var handleErr error
+	analyzeRepositories(entries, workers, fetch, newAnalyzer, func(r repositoryResult) {
+		if handleErr != nil {
+			return
+		}
+		if r.fetchErr != nil {
+			handleErr = r.fetchErr
+			return
+		}
+		if r.analyzeErr != nil {
+			log.Println("Error:", r.analyzeErr)
+		}
+
+		commit := commitsBySHA[r.commit]
+		counters := r.result.Counters
+		fmt.Printf("%s,%s,%s,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d\n",
+			commit.Date.Format(time.DateOnly),
+			commit.SHA,
+			commit.Tag,
+			counters.FuncTotal,
+			counters.FuncGeneric,
+			counters.MethodTotal,
+			counters.MethodWithGenericReceiver,
+			counters.StructTotal,
+			counters.StructGeneric,
+			counters.TypeDecl,
+			counters.GenericTypeDecl,
+			counters.GenericTypeSet,
+			counters.InstantiationExplicit,
+		)
+
+		if err := sqliteDB.AddHistoryEntry(projectName, commit, r.result.Go, counters); err != nil {
+			handleErr = err
+		}
+	})
+	return handleErr
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"GoParser/model"
+	"go/ast"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// instantiationSite is an explicit instantiation like Map[int, string](...) or List[int]{}
+type instantiationSite struct {
+	base         *ast.Ident // name of the instantiated function or type
+	crossPackage bool
+}
+
+var predeclaredTypes = map[string]bool{
+	"any": true, "bool": true, "byte": true, "comparable": true, "complex64": true, "complex128": true,
+	"error": true, "float32": true, "float64": true, "int": true, "int8": true, "int16": true,
+	"int32": true, "int64": true, "rune": true, "string": true, "uint": true, "uint8": true,
+	"uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+}
+
+// countInstantiations counts the instantiation sites of a file. Syntactically only explicit
+// instantiations can be found; with go/types also inferred ones from types.Info.Instances.
+func countInstantiations(file *ast.File, pkgInfo *packageInfo, counters *model.GenericCounters) {
+	sites := findExplicitInstantiations(file, pkgInfo)
+	if pkgInfo.typesInfo == nil {
+		for _, site := range sites {
+			countInstantiation(counters, true, site.crossPackage)
+		}
+		return
+	}
+
+	// Every identifier go/types recorded an instance for is one instantiation site.
+	// It is explicit if it is the operand of an index expression, otherwise the type arguments were inferred.
+	indexBases := make(map[*ast.Ident]bool)
+	inspectWithoutReceivers(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.IndexExpr:
+			if base := indexBase(node.X); base != nil {
+				indexBases[base] = true
+			}
+		case *ast.IndexListExpr:
+			if base := indexBase(node.X); base != nil {
+				indexBases[base] = true
+			}
+		case *ast.Ident:
+			if _, ok := pkgInfo.typesInfo.Instances[node]; ok {
+				crossPackage := false
+				if obj := pkgInfo.typesInfo.Uses[node]; obj != nil && obj.Pkg() != nil {
+					crossPackage = obj.Pkg() != pkgInfo.typesPkg
+				}
+				countInstantiation(counters, indexBases[node], crossPackage)
+			}
+		}
+		return true
+	})
+
+	// Sites whose operand could not be resolved (e.g. missing dependency) keep the syntactic result
+	for _, site := range sites {
+		if _, ok := pkgInfo.typesInfo.Instances[site.base]; !ok && pkgInfo.typesInfo.Uses[site.base] == nil {
+			countInstantiation(counters, true, site.crossPackage)
+		}
+	}
+}
+
+func countInstantiation(counters *model.GenericCounters, explicit, crossPackage bool) {
+	if explicit {
+		counters.InstantiationExplicit++
+	} else {
+		counters.InstantiationInferred++
+	}
+	if crossPackage {
+		counters.InstantiationCrossPackage++
+	} else {
+		counters.InstantiationSamePackage++
+	}
+}
+
+// findExplicitInstantiations finds index expressions that instantiate a generic function or type.
+// Since a[i] is ambiguous without type information, an *ast.IndexExpr is only taken as
+// instantiation if it is in a type position, if its operand is a generic declaration of the
+// package, or if it is qualified by an import and its index looks like a type.
+// Receivers like (b Box[T]) declare type parameters and are not counted.
+func findExplicitInstantiations(file *ast.File, pkgInfo *packageInfo) []instantiationSite {
+	imports := importNames(file)
+	typePositions := collectTypePositions(file)
+
+	var sites []instantiationSite
+	inspectWithoutReceivers(file, func(n ast.Node) bool {
+		var x ast.Expr
+		var firstIndex ast.Expr
+		isInstantiation := false
+
+		switch node := n.(type) {
+		case *ast.IndexExpr:
+			x, firstIndex = node.X, node.Index
+			isInstantiation = typePositions[node]
+		case *ast.IndexListExpr:
+			// Multiple indices are only valid for type arguments
+			x, firstIndex = node.X, node.Indices[0]
+			isInstantiation = true
+		default:
+			return true
+		}
+
+		var base *ast.Ident
+		crossPackage := false
+		switch operand := x.(type) {
+		case *ast.Ident:
+			base = operand
+			isInstantiation = isInstantiation || pkgInfo.genericFuncs[operand.Name] || isGenericTypeName(operand.Name, pkgInfo)
+		case *ast.SelectorExpr:
+			pkgIdent, ok := operand.X.(*ast.Ident)
+			if !ok || !imports[pkgIdent.Name] {
+				return true
+			}
+			base = operand.Sel
+			crossPackage = true
+			isInstantiation = isInstantiation || looksLikeType(firstIndex, pkgInfo)
+		default:
+			return true
+		}
+
+		if isInstantiation {
+			sites = append(sites, instantiationSite{base: base, crossPackage: crossPackage})
+		}
+		return true
+	})
+
+	return sites
+}
+
+// inspectWithoutReceivers works like ast.Inspect but does not descend into method receivers
+func inspectWithoutReceivers(file *ast.File, visit func(ast.Node) bool) {
+	var inspect func(ast.Node) bool
+	inspect = func(n ast.Node) bool {
+		if funcDecl, ok := n.(*ast.FuncDecl); ok && funcDecl.Recv != nil {
+			if !visit(n) {
+				return false
+			}
+			ast.Inspect(funcDecl.Name, inspect)
+			ast.Inspect(funcDecl.Type, inspect)
+			if funcDecl.Body != nil {
+				ast.Inspect(funcDecl.Body, inspect)
+			}
+			return false
+		}
+		return visit(n)
+	}
+	ast.Inspect(file, inspect)
+}
+
+// indexBase returns the identifier naming the operand of an index expression (F or pkg.F)
+func indexBase(x ast.Expr) *ast.Ident {
+	switch operand := x.(type) {
+	case *ast.Ident:
+		return operand
+	case *ast.SelectorExpr:
+		return operand.Sel
+	}
+	return nil
+}
+
+func isGenericTypeName(name string, pkgInfo *packageInfo) bool {
+	typeSpec, ok := pkgInfo.typeSpecs[name]
+	return ok && typeSpec.TypeParams != nil && len(typeSpec.TypeParams.List) > 0
+}
+
+// looksLikeType reports whether an index can only be a type argument and not a value
+func looksLikeType(expr ast.Expr, pkgInfo *packageInfo) bool {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		_, isPackageType := pkgInfo.typeSpecs[e.Name]
+		return predeclaredTypes[e.Name] || isPackageType
+	case *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType, *ast.StructType, *ast.IndexExpr, *ast.IndexListExpr:
+		return true
+	}
+	return false
+}
+
+// collectTypePositions returns all expressions of a file that must denote a type
+func collectTypePositions(file *ast.File) map[ast.Expr]bool {
+	positions := make(map[ast.Expr]bool)
+
+	var mark func(ast.Expr)
+	mark = func(expr ast.Expr) {
+		if expr == nil {
+			return
+		}
+		positions[expr] = true
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			mark(e.X)
+		case *ast.ParenExpr:
+			mark(e.X)
+		case *ast.ArrayType:
+			mark(e.Elt)
+		case *ast.MapType:
+			mark(e.Key)
+			mark(e.Value)
+		case *ast.ChanType:
+			mark(e.Value)
+		case *ast.Ellipsis:
+			mark(e.Elt)
+		case *ast.IndexExpr:
+			mark(e.Index)
+		case *ast.IndexListExpr:
+			for _, index := range e.Indices {
+				mark(index)
+			}
+		}
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.Field:
+			mark(node.Type)
+		case *ast.ValueSpec:
+			mark(node.Type)
+		case *ast.CompositeLit:
+			mark(node.Type)
+		case *ast.TypeSpec:
+			mark(node.Type)
+		case *ast.TypeAssertExpr:
+			mark(node.Type)
+		case *ast.CallExpr:
+			if ident, ok := node.Fun.(*ast.Ident); ok && (ident.Name == "new" || ident.Name == "make") && len(node.Args) > 0 {
+				mark(node.Args[0])
+			}
+		}
+		return true
+	})
+
+	return positions
+}
+
+var majorVersionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// importNames returns the names under which the imports of a file are referenced
+func importNames(file *ast.File) map[string]bool {
+	names := make(map[string]bool)
+	for _, spec := range file.Imports {
+		names[importName(spec)] = true
+	}
+	return names
+}
+
+// importPaths maps the local names of a file's imports to their import paths
+func importPaths(file *ast.File) map[string]string {
+	paths := make(map[string]string)
+	for _, spec := range file.Imports {
+		if importPath, err := strconv.Unquote(spec.Path.Value); err == nil {
+			paths[importName(spec)] = importPath
+		}
+	}
+	return paths
+}
+
+// importName returns the local name of an import. Without an explicit name the last
+// path element is used, ignoring major version suffixes like /v2 or .v3 (gopkg.in).
+func importName(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	importPath, err := strconv.Unquote(spec.Path.Value)
+	if err != nil {
+		return ""
+	}
+	name := path.Base(importPath)
+	if majorVersionSuffix.MatchString(name) && path.Dir(importPath) != "." {
+		name = path.Base(path.Dir(importPath))
+	}
+	if base, _, found := strings.Cut(name, ".v"); found && strings.HasPrefix(importPath, "gopkg.in/") {
+		name = base
+	}
+	return name
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"GoParser/model"
+	"go/ast"
+	"go/types"
+)
+
+// stdlibIterators lists functions of the standard library (Go 1.23 and 1.24) that return
+// an iterator, so that ranging over their result is recognized without go/types
+var stdlibIterators = map[string]map[string]bool{
+	"maps":    {"All": true, "Keys": true, "Values": true},
+	"slices":  {"All": true, "Backward": true, "Values": true, "Chunk": true},
+	"strings": {"Lines": true, "SplitSeq": true, "SplitAfterSeq": true, "FieldsSeq": true, "FieldsFuncSeq": true},
+	"bytes":   {"Lines": true, "SplitSeq": true, "SplitAfterSeq": true, "FieldsSeq": true, "FieldsFuncSeq": true},
+}
+
+// iteratorFuncs holds the names of the package level functions and methods that return an
+// iterator or are an iterator themselves (func Count(yield func(int) bool))
+type iteratorFuncs struct {
+	funcs   map[string]bool
+	methods map[string]bool // nur über den Namen, der Receiver-Typ wird nicht unterschieden
+}
+
+// collectIteratorFuncs runs over all files of a package in the first pass
+func collectIteratorFuncs(files []*ast.File) iteratorFuncs {
+	iterators := iteratorFuncs{funcs: make(map[string]bool), methods: make(map[string]bool)}
+	for _, file := range files {
+		imports := importPaths(file)
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || !(returnsIterator(funcDecl.Type, imports) || isYieldFunc(funcDecl.Type)) {
+				continue
+			}
+			if funcDecl.Recv == nil {
+				iterators.funcs[funcDecl.Name.Name] = true
+			} else {
+				iterators.methods[funcDecl.Name.Name] = true
+			}
+		}
+	}
+	return iterators
+}
+
+// isIteratorType reports whether expr is iter.Seq, iter.Seq2 (instantiated or not) or a
+// function type of the form func(yield func(...) bool)
+func isIteratorType(expr ast.Expr, imports map[string]string) bool {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return isIteratorType(e.X, imports)
+	case *ast.IndexExpr:
+		return isIteratorType(e.X, imports)
+	case *ast.IndexListExpr:
+		return isIteratorType(e.X, imports)
+	case *ast.SelectorExpr:
+		pkg, ok := e.X.(*ast.Ident)
+		return ok && imports[pkg.Name] == "iter" && (e.Sel.Name == "Seq" || e.Sel.Name == "Seq2")
+	case *ast.FuncType:
+		return isYieldFunc(e)
+	}
+	return false
+}
+
+// isYieldFunc reports whether a function type has the shape func(yield func(...) bool)
+func isYieldFunc(funcType *ast.FuncType) bool {
+	if funcType.Params.NumFields() != 1 || funcType.Results.NumFields() != 0 {
+		return false
+	}
+	yield, ok := funcType.Params.List[0].Type.(*ast.FuncType)
+	return ok && yield.Results.NumFields() == 1 && isIdentNamed(yield.Results.List[0].Type, "bool")
+}
+
+// returnsIterator reports whether a function returns exactly one iterator
+func returnsIterator(funcType *ast.FuncType, imports map[string]string) bool {
+	return funcType.Results.NumFields() == 1 && isIteratorType(funcType.Results.List[0].Type, imports)
+}
+
+// countIterators counts the range-over-func iterators of a file (Go 1.23): functions and
+// methods returning an iterator, range loops over function values and uses of iter.Pull
+func countIterators(file *ast.File, pkgInfo *packageInfo, counters *model.GenericCounters) {
+	imports := importPaths(file)
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncDecl:
+			if returnsIterator(node.Type, imports) {
+				counters.IterSeqFunc++
+			}
+
+		case *ast.RangeStmt:
+			if isRangeOverFunc(node.X, imports, pkgInfo) {
+				counters.RangeOverFunc++
+			}
+
+		case *ast.SelectorExpr:
+			if pkg, ok := node.X.(*ast.Ident); ok && pkg.Obj == nil && imports[pkg.Name] == "iter" {
+				if node.Sel.Name == "Pull" || node.Sel.Name == "Pull2" {
+					counters.IterPull++
+				}
+			}
+		}
+		return true
+	})
+}
+
+// isRangeOverFunc reports whether the range expression is a function value. Syntactically
+// function literals, parameters and variables of an iterator type, iterator functions of the
+// package and calls returning an iterator are recognized; with go/types every function value.
+func isRangeOverFunc(expr ast.Expr, imports map[string]string, pkgInfo *packageInfo) bool {
+	if pkgInfo.typesInfo != nil {
+		if isFuncValue, ok := isFuncValueWithTypes(expr, pkgInfo.typesInfo); ok {
+			return isFuncValue
+		}
+	}
+
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return isRangeOverFunc(e.X, imports, pkgInfo)
+
+	case *ast.FuncLit:
+		return true
+
+	case *ast.Ident:
+		// Funktionen aus anderen Dateien des Pakets werden vom Parser nicht aufgelöst
+		if e.Obj == nil {
+			return pkgInfo.iterators.funcs[e.Name]
+		}
+		switch decl := e.Obj.Decl.(type) {
+		case *ast.FuncDecl:
+			return pkgInfo.iterators.funcs[decl.Name.Name]
+		case *ast.Field:
+			return isIteratorType(decl.Type, imports)
+		case *ast.ValueSpec:
+			return decl.Type != nil && isIteratorType(decl.Type, imports)
+		case *ast.AssignStmt:
+			// seq := maps.Keys(m) bzw. seq := func(yield func(int) bool) {...}
+			if len(decl.Lhs) == len(decl.Rhs) {
+				for i, lhs := range decl.Lhs {
+					if isIdentNamed(lhs, e.Name) {
+						return isRangeOverFunc(decl.Rhs[i], imports, pkgInfo)
+					}
+				}
+			}
+		}
+		return false
+
+	case *ast.SelectorExpr:
+		// Methodenwert, z.B. range list.All
+		if pkg, ok := e.X.(*ast.Ident); ok && pkg.Obj == nil {
+			if _, isImport := imports[pkg.Name]; isImport {
+				return false
+			}
+		}
+		return pkgInfo.iterators.methods[e.Sel.Name]
+
+	case *ast.CallExpr:
+		switch fun := e.Fun.(type) {
+		case *ast.Ident:
+			return pkgInfo.iterators.funcs[fun.Name]
+		case *ast.IndexExpr:
+			// Explizit instanziierte Iterator-Funktion, z.B. Count[int](10)
+			ident, ok := fun.X.(*ast.Ident)
+			return ok && pkgInfo.iterators.funcs[ident.Name]
+		case *ast.SelectorExpr:
+			if pkg, ok := fun.X.(*ast.Ident); ok && pkg.Obj == nil {
+				if importPath, ok := imports[pkg.Name]; ok {
+					return stdlibIterators[importPath][fun.Sel.Name]
+				}
+			}
+			return pkgInfo.iterators.methods[fun.Sel.Name]
+		}
+	}
+	return false
+}
+
+// isFuncValueWithTypes uses go/types to decide whether expr has a function type.
+// ok is false if go/types has no information about expr.
+func isFuncValueWithTypes(expr ast.Expr, info *types.Info) (isFuncValue bool, ok bool) {
+	var t types.Type
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		obj := info.Uses[e]
+		if obj == nil {
+			return false, false
+		}
+		t = obj.Type()
+	case *ast.SelectorExpr:
+		obj := info.Uses[e.Sel]
+		if obj == nil {
+			return false, false
+		}
+		t = obj.Type()
+	case *ast.CallExpr:
+		fun := ast.Unparen(e.Fun)
+		if index, isIndex := fun.(*ast.IndexExpr); isIndex {
+			fun = index.X
+		} else if indexList, isIndexList := fun.(*ast.IndexListExpr); isIndexList {
+			fun = indexList.X
+		}
+		var ident *ast.Ident
+		switch f := fun.(type) {
+		case *ast.Ident:
+			ident = f
+		case *ast.SelectorExpr:
+			ident = f.Sel
+		}
+		if ident == nil {
+			return false, false
+		}
+		fn, isFunc := info.Uses[ident].(*types.Func)
+		if !isFunc {
+			return false, false
+		}
+		results := fn.Type().(*types.Signature).Results()
+		if results.Len() != 1 {
+			return false, true
+		}
+		t = results.At(0).Type()
+	default:
+		return false, false
+	}
+	if t == nil {
+		return false, false
+	}
+	_, isSignature := t.Underlying().(*types.Signature)
+	return isSignature, true
+}
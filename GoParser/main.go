@@ -3,8 +3,11 @@ package main
 import (
 	"GoParser/database"
 	"GoParser/model"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"os"
 	"path/filepath"
 
 	utils "GoParser/utils"
@@ -13,6 +16,10 @@ import (
 func aggregateCounters(target *model.GenericCounters, source model.GenericCounters) {
 	target.FuncTotal += source.FuncTotal
 	target.FuncGeneric += source.FuncGeneric
+	target.FuncGenericTrivialBound += source.FuncGenericTrivialBound
+	target.FuncGenericNonTrivialBound += source.FuncGenericNonTrivialBound
+	target.FuncGenericInlineUnion += source.FuncGenericInlineUnion
+	target.FuncGenericStructBound += source.FuncGenericStructBound
 	target.MethodTotal += source.MethodTotal
 	target.MethodWithGenericReceiver += source.MethodWithGenericReceiver
 	target.MethodWithGenericReceiverTrivialTypeBound += source.MethodWithGenericReceiverTrivialTypeBound
@@ -24,12 +31,74 @@ func aggregateCounters(target *model.GenericCounters, source model.GenericCounte
 	target.TypeDecl += source.TypeDecl
 	target.GenericTypeDecl += source.GenericTypeDecl
 	target.GenericTypeSet += source.GenericTypeSet
+	target.TypeSetInline += source.TypeSetInline
+	target.TypeSetTerms += source.TypeSetTerms
+	target.TypeSetTildeTerms += source.TypeSetTildeTerms
+	target.TypeSetExactTerms += source.TypeSetExactTerms
+	target.TypeSetEmbedded += source.TypeSetEmbedded
+	target.TypeSetIntersection += source.TypeSetIntersection
+	target.TypeSetMixed += source.TypeSetMixed
+	target.InterfaceTotal += source.InterfaceTotal
+	target.InterfaceGeneric += source.InterfaceGeneric
+	target.InterfaceGenericMethodUsesTypeParam += source.InterfaceGenericMethodUsesTypeParam
+	target.FuncTypeGeneric += source.FuncTypeGeneric
+	target.MapTypeGeneric += source.MapTypeGeneric
+	target.SliceTypeGeneric += source.SliceTypeGeneric
+	target.ChanTypeGeneric += source.ChanTypeGeneric
+	target.AliasTotal += source.AliasTotal
+	target.AliasGeneric += source.AliasGeneric
+	target.AliasOfInstantiatedGeneric += source.AliasOfInstantiatedGeneric
+	target.InstantiationExplicit += source.InstantiationExplicit
+	target.InstantiationInferred += source.InstantiationInferred
+	target.InstantiationSamePackage += source.InstantiationSamePackage
+	target.InstantiationCrossPackage += source.InstantiationCrossPackage
+	target.ConstraintAny += source.ConstraintAny
+	target.ConstraintComparable += source.ConstraintComparable
+	target.ConstraintOrdered += source.ConstraintOrdered
+	target.ConstraintInlineUnion += source.ConstraintInlineUnion
+	target.ConstraintMethodInterface += source.ConstraintMethodInterface
+	target.ConstraintTypeSetInterface += source.ConstraintTypeSetInterface
+	target.ConstraintSelfReferential += source.ConstraintSelfReferential
+	target.ConstraintConcrete += source.ConstraintConcrete
+	target.ConstraintOther += source.ConstraintOther
+	target.CandidateAnyTypeSwitch += source.CandidateAnyTypeSwitch
+	target.CandidateDuplicateFunc += source.CandidateDuplicateFunc
+	target.CandidateDuplicateFamily += source.CandidateDuplicateFamily
+	target.CandidateSortInterface += source.CandidateSortInterface
+	target.GenericAPIImports += source.GenericAPIImports
+	target.GenericAPIUses += source.GenericAPIUses
+	target.IterSeqFunc += source.IterSeqFunc
+	target.RangeOverFunc += source.RangeOverFunc
+	target.IterPull += source.IterPull
+}
+
+func aggregateResult(target *model.AnalysisResult, source model.AnalysisResult) {
+	aggregateCounters(&target.Counters, source.Counters)
+	for category, counters := range source.ByCategory {
+		if target.ByCategory == nil {
+			target.ByCategory = make(map[string]model.GenericCounters)
+		}
+		categoryCounters := target.ByCategory[category]
+		aggregateCounters(&categoryCounters, counters)
+		target.ByCategory[category] = categoryCounters
+	}
+	for _, module := range source.ByModule {
+		addModuleCounters(target, module, module.Counters)
+	}
+	target.Declarations = append(target.Declarations, source.Declarations...)
+	aggregateTypeParamStats(&target.TypeParams, source.TypeParams)
+	target.Candidates = append(target.Candidates, source.Candidates...)
+	aggregateGenericAPIUsage(&target.GenericAPIs, source.GenericAPIs)
 }
 
 func printCountersSummary(counters model.GenericCounters, title string) {
 	fmt.Println()
 	fmt.Printf("%s:\n", title)
 	fmt.Printf("FuncGeneric: %v\n", counters.FuncGeneric)
+	fmt.Printf("FuncGenericTrivialBound: %v\n", counters.FuncGenericTrivialBound)
+	fmt.Printf("FuncGenericNonTrivialBound: %v\n", counters.FuncGenericNonTrivialBound)
+	fmt.Printf("FuncGenericInlineUnion: %v\n", counters.FuncGenericInlineUnion)
+	fmt.Printf("FuncGenericStructBound: %v\n", counters.FuncGenericStructBound)
 	fmt.Printf("MethodWithGenericReceiver: %v\n", counters.MethodWithGenericReceiver)
 	fmt.Printf("MethodWithGenericReceiverTrivialTypeBound: %v\n", counters.MethodWithGenericReceiverTrivialTypeBound)
 	fmt.Printf("MethodWithGenericReceiverNonTrivialTypeBound: %v\n", counters.MethodWithGenericReceiverNonTrivialTypeBound)
@@ -38,13 +107,63 @@ func printCountersSummary(counters model.GenericCounters, title string) {
 	fmt.Printf("StructAsTypeBound: %v\n", counters.StructAsTypeBound)
 	fmt.Printf("GenericTypeDecl: %v\n", counters.GenericTypeDecl)
 	fmt.Printf("GenericTypeSet: %v\n", counters.GenericTypeSet)
+	fmt.Printf("TypeSetInline: %v\n", counters.TypeSetInline)
+	fmt.Printf("TypeSetTerms: %v\n", counters.TypeSetTerms)
+	fmt.Printf("TypeSetTildeTerms: %v\n", counters.TypeSetTildeTerms)
+	fmt.Printf("TypeSetExactTerms: %v\n", counters.TypeSetExactTerms)
+	fmt.Printf("TypeSetEmbedded: %v\n", counters.TypeSetEmbedded)
+	fmt.Printf("TypeSetIntersection: %v\n", counters.TypeSetIntersection)
+	fmt.Printf("TypeSetMixed: %v\n", counters.TypeSetMixed)
+	fmt.Printf("InterfaceGeneric: %v\n", counters.InterfaceGeneric)
+	fmt.Printf("InterfaceGenericMethodUsesTypeParam: %v\n", counters.InterfaceGenericMethodUsesTypeParam)
+	fmt.Printf("FuncTypeGeneric: %v\n", counters.FuncTypeGeneric)
+	fmt.Printf("MapTypeGeneric: %v\n", counters.MapTypeGeneric)
+	fmt.Printf("SliceTypeGeneric: %v\n", counters.SliceTypeGeneric)
+	fmt.Printf("ChanTypeGeneric: %v\n", counters.ChanTypeGeneric)
+	fmt.Printf("AliasTotal: %v\n", counters.AliasTotal)
+	fmt.Printf("AliasGeneric: %v\n", counters.AliasGeneric)
+	fmt.Printf("AliasOfInstantiatedGeneric: %v\n", counters.AliasOfInstantiatedGeneric)
+	fmt.Printf("InstantiationExplicit: %v\n", counters.InstantiationExplicit)
+	fmt.Printf("InstantiationInferred: %v\n", counters.InstantiationInferred)
+	fmt.Printf("InstantiationSamePackage: %v\n", counters.InstantiationSamePackage)
+	fmt.Printf("InstantiationCrossPackage: %v\n", counters.InstantiationCrossPackage)
+	fmt.Printf("ConstraintAny: %v\n", counters.ConstraintAny)
+	fmt.Printf("ConstraintComparable: %v\n", counters.ConstraintComparable)
+	fmt.Printf("ConstraintOrdered: %v\n", counters.ConstraintOrdered)
+	fmt.Printf("ConstraintInlineUnion: %v\n", counters.ConstraintInlineUnion)
+	fmt.Printf("ConstraintMethodInterface: %v\n", counters.ConstraintMethodInterface)
+	fmt.Printf("ConstraintTypeSetInterface: %v\n", counters.ConstraintTypeSetInterface)
+	fmt.Printf("ConstraintSelfReferential: %v\n", counters.ConstraintSelfReferential)
+	fmt.Printf("ConstraintConcrete: %v\n", counters.ConstraintConcrete)
+	fmt.Printf("ConstraintOther: %v\n", counters.ConstraintOther)
+	fmt.Printf("CandidateAnyTypeSwitch: %v\n", counters.CandidateAnyTypeSwitch)
+	fmt.Printf("CandidateDuplicateFunc: %v\n", counters.CandidateDuplicateFunc)
+	fmt.Printf("CandidateDuplicateFamily: %v\n", counters.CandidateDuplicateFamily)
+	fmt.Printf("CandidateSortInterface: %v\n", counters.CandidateSortInterface)
+	fmt.Printf("GenericAPIImports: %v\n", counters.GenericAPIImports)
+	fmt.Printf("GenericAPIUses: %v\n", counters.GenericAPIUses)
+	fmt.Printf("IterSeqFunc: %v\n", counters.IterSeqFunc)
+	fmt.Printf("RangeOverFunc: %v\n", counters.RangeOverFunc)
+	fmt.Printf("IterPull: %v\n", counters.IterPull)
 }
 
+// databasePath ist die SQLite-Datenbank mit den Ergebnissen aller Läufe
+const databasePath = "generic_counters.db"
+
+// csvHeader enthält die Spalten von printCSVRow
+const csvHeader = "Repository,FuncTotal,FuncGeneric,FuncGenericTrivialBound,FuncGenericNonTrivialBound,FuncGenericInlineUnion,FuncGenericStructBound,MethodTotal,MethodWithGenericReceiver,MethodWithGenericReceiverTrivialTypeBound,MethodWithGenericReceiverNonTrivialTypeBound,StructTotal,StructGeneric,StructGenericNonTrivialBound,StructAsTypeBound,TypeDecl,GenericTypeDecl,GenericTypeSet,TypeSetInline,TypeSetTerms,TypeSetTildeTerms,TypeSetExactTerms,TypeSetEmbedded,TypeSetIntersection,TypeSetMixed,InterfaceTotal,InterfaceGeneric,InterfaceGenericMethodUsesTypeParam,FuncTypeGeneric,MapTypeGeneric,SliceTypeGeneric,ChanTypeGeneric,AliasTotal,AliasGeneric,AliasOfInstantiatedGeneric,InstantiationExplicit,InstantiationInferred,InstantiationSamePackage,InstantiationCrossPackage," +
+	"ConstraintAny,ConstraintComparable,ConstraintOrdered,ConstraintInlineUnion,ConstraintMethodInterface,ConstraintTypeSetInterface,ConstraintSelfReferential,ConstraintConcrete,ConstraintOther," +
+	"CandidateAnyTypeSwitch,CandidateDuplicateFunc,CandidateDuplicateFamily,CandidateSortInterface,GenericAPIImports,GenericAPIUses,IterSeqFunc,RangeOverFunc,IterPull"
+
 func printCSVRow(name string, counters model.GenericCounters) {
-	fmt.Printf("%s,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d\n",
+	fmt.Printf("%s,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d\n",
 		name,
 		counters.FuncTotal,
 		counters.FuncGeneric,
+		counters.FuncGenericTrivialBound,
+		counters.FuncGenericNonTrivialBound,
+		counters.FuncGenericInlineUnion,
+		counters.FuncGenericStructBound,
 		counters.MethodTotal,
 		counters.MethodWithGenericReceiver,
 		counters.MethodWithGenericReceiverTrivialTypeBound,
@@ -56,19 +175,91 @@ func printCSVRow(name string, counters model.GenericCounters) {
 		counters.TypeDecl,
 		counters.GenericTypeDecl,
 		counters.GenericTypeSet,
+		counters.TypeSetInline,
+		counters.TypeSetTerms,
+		counters.TypeSetTildeTerms,
+		counters.TypeSetExactTerms,
+		counters.TypeSetEmbedded,
+		counters.TypeSetIntersection,
+		counters.TypeSetMixed,
+		counters.InterfaceTotal,
+		counters.InterfaceGeneric,
+		counters.InterfaceGenericMethodUsesTypeParam,
+		counters.FuncTypeGeneric,
+		counters.MapTypeGeneric,
+		counters.SliceTypeGeneric,
+		counters.ChanTypeGeneric,
+		counters.AliasTotal,
+		counters.AliasGeneric,
+		counters.AliasOfInstantiatedGeneric,
+		counters.InstantiationExplicit,
+		counters.InstantiationInferred,
+		counters.InstantiationSamePackage,
+		counters.InstantiationCrossPackage,
+		counters.ConstraintAny,
+		counters.ConstraintComparable,
+		counters.ConstraintOrdered,
+		counters.ConstraintInlineUnion,
+		counters.ConstraintMethodInterface,
+		counters.ConstraintTypeSetInterface,
+		counters.ConstraintSelfReferential,
+		counters.ConstraintConcrete,
+		counters.ConstraintOther,
+		counters.CandidateAnyTypeSwitch,
+		counters.CandidateDuplicateFunc,
+		counters.CandidateDuplicateFamily,
+		counters.CandidateSortInterface,
+		counters.GenericAPIImports,
+		counters.GenericAPIUses,
+		counters.IterSeqFunc,
+		counters.RangeOverFunc,
+		counters.IterPull,
 	)
 }
 
 func main() {
-	config, err := utils.SetupEnvironment()
+	resume := flag.Bool("resume", false, "skip repositories that already have a result in the database")
+	fresh := flag.Bool("fresh", false, "delete an existing database before the run")
+	workers := flag.Int("workers", 4, "number of repositories downloaded and analysed in parallel")
+	offline := flag.Bool("offline", false, "analyse only archives from the archive cache without contacting the source hosts")
+	history := flag.String("history", "", "in local mode, analyse the git history of the project instead of the working tree: monthly, tags or every N commits")
+	defaultRef := flag.String("ref", "", "branch, tag, commit SHA or date (YYYY-MM-DD) analysed for repositories without a ref in the input file")
+	goVersions := flag.Bool("go-versions", false, "print the adoption of generics per Go language version declared in go.mod from the database and exit")
+	flag.Parse()
+
+	if *resume && *fresh {
+		log.Fatalf("-resume and -fresh cannot be combined")
+	}
+
+	// Der Bericht liest nur vorhandene Ergebnisse, dafür werden weder Secrets noch Netzwerkzugriff benötigt
+	if *goVersions {
+		if *fresh {
+			log.Fatalf("-go-versions and -fresh cannot be combined")
+		}
+		// NewSQLiteDB würde eine fehlende Datenbank leer anlegen und der Bericht wäre ohne Hinweis leer
+		if _, err := os.Stat(databasePath); err != nil {
+			log.Fatalf("Cannot read results for -go-versions: %v - run an analysis first", err)
+		}
+		sqliteDB, err := database.NewSQLiteDB(databasePath, utils.GetColumns(), false)
+		if err != nil {
+			log.Fatalf("Failed to open database: %v", err)
+		}
+		defer sqliteDB.Close()
+		if err := printGoVersionReport(sqliteDB); err != nil {
+			log.Fatalf("Failed to create go version report: %v", err)
+		}
+		return
+	}
+
+	config, err := utils.SetupEnvironment(*offline)
 	if err != nil {
 		log.Fatalf("Failed to set up environment: %v", err)
 	}
 
 	counterOverEveryRepository := model.GenericCounters{}
 
-	// Datenbank erstellen
-	sqliteDB, err := database.NewSQLiteDB("generic_counters.db", utils.GetColumns())
+	// Datenbank öffnen bzw. erstellen. Vorhandene Ergebnisse bleiben erhalten, außer bei -fresh
+	sqliteDB, err := database.NewSQLiteDB(databasePath, utils.GetColumns(), *fresh)
 	if err != nil {
 		log.Fatalf("Failed to create database: %v", err)
 	}
@@ -79,106 +270,210 @@ func main() {
 		}
 	}()
 
-	astAnalyzer := NewASTAnalyzer()
+	// Bei -resume werden alle Repositories übersprungen, die bereits ein Ergebnis für dieselbe Ref in der Datenbank haben.
+	// Zusätzlich wird jedes Repository pro Lauf nur einmal analysiert, auch wenn es mehrfach im Input steht.
+	analyzedRepositories := make(map[string]string)
+	if *resume {
+		analyzedRepositories, err = sqliteDB.AnalyzedRepositories()
+		if err != nil {
+			log.Fatalf("Failed to read analysed repositories: %v", err)
+		}
+		log.Printf("Resuming run: %d repositories already analysed", len(analyzedRepositories))
+	}
+
+	// Jeder Worker erhält einen eigenen Analyzer
+	newAnalyzer := func() ASTAnalyzer {
+		if config.AnalysisMode == utils.AnalysisModeTypes {
+			return NewTypesAnalyzer(config.ModCache)
+		}
+		return NewASTAnalyzer()
+	}
+	if config.AnalysisMode == utils.AnalysisModeTypes {
+		log.Printf("Using semantic analysis (go/types)")
+	}
 
 	// Prüfe ob lokaler Modus aktiviert ist
 	if config.LocalProject != "" {
 		// === LOKALER MODUS ===
 		log.Printf("Running in LOCAL mode for project: %s", config.LocalProject)
 
-		files, err := utils.FetchLocalGoFiles(config.LocalProject)
+		projectName := "local/" + filepath.Base(config.LocalProject)
+
+		if *history != "" {
+			// Zeitreihe über die Git-Historie statt des Arbeitsverzeichnisses
+			if err := analyzeHistory(sqliteDB, config.LocalProject, projectName, *history, *workers, *resume, newAnalyzer); err != nil {
+				log.Fatalf("Failed to analyse history: %v", err)
+			}
+			return
+		}
+
+		if _, ok := analyzedRepositories[projectName]; ok {
+			log.Printf("Skipping already analysed project: %s", projectName)
+			return
+		}
+
+		tree, err := utils.OpenLocalTree(config.LocalProject)
 		if err != nil {
 			log.Fatalf("Failed to load local files: %v", err)
 		}
+		defer tree.Close()
 
-		log.Printf("Found %d .go files in local project", len(files))
-
-		countersForProject := model.GenericCounters{}
+		log.Printf("Found %d files in local project", len(tree.Paths()))
 
 		// CSV-Header ausgeben
-		fmt.Println("Repository,FuncTotal,FuncGeneric,MethodTotal,MethodWithGenericReceiver,MethodWithGenericReceiverTrivialTypeBound,MethodWithGenericReceiverNonTrivialTypeBound,StructTotal,StructGeneric,StructGenericNonTrivialBound,StructAsTypeBound,TypeDecl,GenericTypeDecl,GenericTypeSet")
-
-		for _, file := range files {
-			counts, err := astAnalyzer.AnalyzeFile(file)
-			if err != nil {
-				log.Println("Error:", err)
-			} else {
-				aggregateCounters(&countersForProject, counts)
-			}
+		fmt.Println(csvHeader)
+
+		// Dateien werden paketweise analysiert, damit Type Bounds über Dateigrenzen hinweg bekannt sind
+		resultForProject, err := newAnalyzer().AnalyzeTree(tree)
+		if err != nil {
+			log.Println("Error:", err)
 		}
+		countersForProject := resultForProject.Counters
 
 		// Ausgabe für lokales Projekt
-		projectName := "local/" + filepath.Base(config.LocalProject)
 		printCSVRow(projectName, countersForProject)
 
-		// In Datenbank speichern
-		if err := sqliteDB.AddGenericCountersEntry(projectName, countersForProject); err != nil {
+		// In Datenbank speichern, alle Tabellen in einer Transaktion, damit -resume kein Repository mit fehlenden Details überspringt
+		if err := sqliteDB.AddRepositoryResult(projectName, "", "", resultForProject, constraintFrequencies(resultForProject.Declarations)); err != nil {
 			log.Fatalf("Failed to add entry to database: %v", err)
 		}
 
 		// Gesamt-Statistik
 		printCountersSummary(countersForProject, "Counter for local project")
+		printCountersSummary(resultForProject.ByCategory[model.FileCategoryProduction], "Counter for local project (production code only)")
 
 		return
 	}
 
-	// === GITHUB MODUS (wie bisher) ===
+	// === REPOSITORY MODUS ===
 	entries, err := utils.GetOwnerAndRepo(config.CSVPath)
 	if err != nil {
 		log.Fatalf("Failed to read CSV file: %v", err)
 	}
+	if config.Token == "" && utils.RequiresGitHubToken(entries, *offline) {
+		log.Fatal("GITHUB_TOKEN not found - set GOPARSER_SECRETS_PATH or use VSCode launch.json")
+	}
 
 	// CSV-Header anpassen
-	fmt.Println("Repository,FuncTotal,FuncGeneric,MethodTotal,MethodWithGenericReceiver,MethodWithGenericReceiverTrivialTypeBound,MethodWithGenericReceiverNonTrivialTypeBound,StructTotal,StructGeneric,StructGenericNonTrivialBound,StructAsTypeBound,TypeDecl,GenericTypeDecl,GenericTypeSet")
+	fmt.Println(csvHeader)
 
+	// Bereits analysierte und doppelte Repositories vorab aussortieren, damit die Reihenfolge der Ausgabe feststeht
+	var repositories []utils.RepositoryEntry
+	seen := make(map[string]bool)
 	for _, repository := range entries {
-		files, err := utils.FetchGoFilesList(repository[0], repository[1], config.Token)
-		if err != nil {
-			log.Println(err)
-		} else {
-			countersForEntireRepo := model.GenericCounters{}
-
-			for _, file := range files {
-				counts, err := astAnalyzer.AnalyzeFile(file)
-				if err != nil {
-					log.Println("Error:", err)
-				} else {
-					aggregateCounters(&countersForEntireRepo, counts)
-				}
-			}
+		repoName := repository.Name()
+		// Archive haben keine Refs, ihre URL bestimmt den Inhalt; Module werden über ihre Version ausgewählt
+		if repository.Ref == "" && repository.Provider != utils.ProviderArchive && repository.Provider != utils.ProviderModule {
+			repository.Ref = *defaultRef
+		}
+		if seen[repoName] {
+			log.Printf("Skipping duplicate repository: %s", repoName)
+			continue
+		}
+		seen[repoName] = true
+		if ref, ok := analyzedRepositories[repoName]; ok && ref == repository.Ref {
+			log.Printf("Skipping already analysed repository: %s", repoName)
+			continue
+		}
+		repositories = append(repositories, repository)
+	}
 
-			// Aggregation auf Repository-Ebene
-			if countersForEntireRepo.FuncGeneric > 0 {
-				counterOverEveryRepository.FuncGeneric++
-			}
-			if countersForEntireRepo.MethodWithGenericReceiver > 0 {
-				counterOverEveryRepository.MethodWithGenericReceiver++
-			}
-			if countersForEntireRepo.GenericTypeDecl > 0 {
-				counterOverEveryRepository.GenericTypeDecl++
-			}
-			if countersForEntireRepo.GenericTypeSet > 0 {
-				counterOverEveryRepository.GenericTypeSet++
-			}
-			if countersForEntireRepo.StructGeneric > 0 {
-				counterOverEveryRepository.StructGeneric++
+	// Gemeinsame Provider für alle Worker, damit das Rate Limit jedes Hosts gemeinsam beachtet wird
+	providerConfig := utils.ProviderConfig{
+		GitHubToken: config.Token,
+		GitLabToken: config.GitLabToken,
+		GiteaToken:  config.GiteaToken,
+		GOPROXY:     config.GOPROXY,
+		ModCache:    config.ModCache,
+		Offline:     *offline,
+		Timeout:     config.RequestTimeout,
+	}
+	if config.ArchiveCacheDir != "" {
+		providerConfig.Cache = utils.NewArchiveCache(config.ArchiveCacheDir)
+		log.Printf("Using archive cache: %s", config.ArchiveCacheDir)
+	}
+	providers := utils.NewSourceProviders(providerConfig)
+	log.Printf("Analysing %d repositories with %d workers", len(repositories), *workers)
+
+	analyzeRepositories(repositories, *workers, providers.FetchRepository, newAnalyzer, func(r repositoryResult) {
+		repoName := r.job.Name()
+		if r.fetchErr != nil {
+			log.Println(r.fetchErr)
+
+			// Endgültig fehlgeschlagene Repositories festhalten, damit sie nicht unbemerkt im Datensatz fehlen
+			attempts := 1
+			var fetchErr *utils.FetchError
+			if errors.As(r.fetchErr, &fetchErr) {
+				attempts = fetchErr.Attempts
 			}
-			if countersForEntireRepo.StructGenericBound > 0 {
-				counterOverEveryRepository.StructGenericBound++
+			if err := sqliteDB.AddFailedRepository(repoName, r.fetchErr.Error(), attempts); err != nil {
+				log.Fatalf("Failed to record failed repository: %v", err)
 			}
+			return
+		}
+		if r.analyzeErr != nil {
+			log.Println("Error:", r.analyzeErr)
+		}
+		resultForEntireRepo := r.result
+		countersForEntireRepo := resultForEntireRepo.Counters
+
+		// Aggregation auf Repository-Ebene
+		if countersForEntireRepo.FuncGeneric > 0 {
+			counterOverEveryRepository.FuncGeneric++
+		}
+		if countersForEntireRepo.FuncGenericNonTrivialBound > 0 {
+			counterOverEveryRepository.FuncGenericNonTrivialBound++
+		}
+		if countersForEntireRepo.MethodWithGenericReceiver > 0 {
+			counterOverEveryRepository.MethodWithGenericReceiver++
+		}
+		if countersForEntireRepo.GenericTypeDecl > 0 {
+			counterOverEveryRepository.GenericTypeDecl++
+		}
+		if countersForEntireRepo.GenericTypeSet > 0 {
+			counterOverEveryRepository.GenericTypeSet++
+		}
+		if countersForEntireRepo.StructGeneric > 0 {
+			counterOverEveryRepository.StructGeneric++
+		}
+		if countersForEntireRepo.StructGenericBound > 0 {
+			counterOverEveryRepository.StructGenericBound++
+		}
+		if countersForEntireRepo.InterfaceGeneric > 0 {
+			counterOverEveryRepository.InterfaceGeneric++
+		}
+		if countersForEntireRepo.AliasGeneric > 0 {
+			counterOverEveryRepository.AliasGeneric++
+		}
+		if countersForEntireRepo.InstantiationExplicit > 0 {
+			counterOverEveryRepository.InstantiationExplicit++
+		}
+		if countersForEntireRepo.InstantiationInferred > 0 {
+			counterOverEveryRepository.InstantiationInferred++
+		}
+		if countersForEntireRepo.GenericAPIUses > 0 {
+			counterOverEveryRepository.GenericAPIUses++
+		}
+		if countersForEntireRepo.IterSeqFunc > 0 {
+			counterOverEveryRepository.IterSeqFunc++
+		}
+		if countersForEntireRepo.RangeOverFunc > 0 {
+			counterOverEveryRepository.RangeOverFunc++
+		}
 
-			log.Printf("Finished repository: %s/%s", repository[0], repository[1])
+		log.Printf("Finished repository: %s at %s", repoName, r.commit)
+		if len(resultForEntireRepo.ByModule) > 1 {
+			log.Printf("%s contains %d modules", repoName, len(resultForEntireRepo.ByModule))
+		}
 
-			// CSV-Ausgabe pro Repo
-			repoName := repository[0] + "/" + repository[1]
-			printCSVRow(repoName, countersForEntireRepo)
+		// CSV-Ausgabe pro Repo
+		printCSVRow(repoName, countersForEntireRepo)
 
-			// In Datenbank speichern
-			if err := sqliteDB.AddGenericCountersEntry(repoName, countersForEntireRepo); err != nil {
-				log.Fatalf("Failed to add entry to database: %v", err)
-			}
+		// In Datenbank speichern, alle Tabellen in einer Transaktion, damit -resume kein Repository mit fehlenden Details überspringt
+		if err := sqliteDB.AddRepositoryResult(repoName, r.job.Ref, r.commit, resultForEntireRepo, constraintFrequencies(resultForEntireRepo.Declarations)); err != nil {
+			log.Fatalf("Failed to add entry to database: %v", err)
 		}
-	}
+	})
 
 	// Gesamt-Statistik am Ende
 	printCountersSummary(counterOverEveryRepository, "Counter over every Repository")
@@ -0,0 +1,13 @@
+package model
+
+// AnalysisResult ist das Ergebnis der Analyse eines Projekts
+type AnalysisResult struct {
+	Counters     GenericCounters
+	ByCategory   map[string]GenericCounters // Zähler je Dateikategorie (FileCategory*), ergeben zusammen Counters
+	ByModule     map[string]ModuleCounters  // Zähler je Modul, Schlüssel ist ModuleCounters.Dir; ergeben zusammen Counters
+	Go           GoDirectives               // Go-Version des Repositories: go.work bzw. go.mod im Wurzelverzeichnis
+	Declarations []GenericDeclaration
+	TypeParams   TypeParamStats
+	Candidates   []GenericCandidate
+	GenericAPIs  GenericAPIUsage
+}
@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// Commit ist ein Stand eines Repositories, der in der historischen Analyse ausgewertet wird
+type Commit struct {
+	SHA  string
+	Date time.Time // Commit-Datum (nicht Author-Datum), damit Rebases die Zeitreihe nicht verfälschen
+	Tag  string    // nur bei Sampling nach Tags gesetzt
+}
@@ -0,0 +1,21 @@
+package model
+
+// Arten von Constraints eines Typparameters (Constraint-Katalog)
+const (
+	ConstraintKindAny              = "any"                // any oder interface{}
+	ConstraintKindComparable       = "comparable"         // comparable
+	ConstraintKindOrdered          = "ordered"            // cmp.Ordered oder constraints.Ordered aus golang.org/x/exp
+	ConstraintKindInlineUnion      = "inline_union"       // direkt angegebene Terme, z.B. ~int | ~string oder interface{ ~int }
+	ConstraintKindMethodInterface  = "method_interface"   // Interface ausschließlich mit Methoden, z.B. fmt.Stringer
+	ConstraintKindTypeSetInterface = "type_set_interface" // benanntes Interface mit Typ-Termen, z.B. Number oder constraints.Integer
+	ConstraintKindSelfReferential  = "self_referential"   // Constraint verwendet den Typparameter selbst, z.B. T Comparer[T]
+	ConstraintKindConcrete         = "concrete"           // Struct oder anderer konkreter Typ, z.B. [T MyStruct]
+	ConstraintKindOther            = "other"              // nicht auflösbar (z.B. importiertes Constraint ohne go/types)
+)
+
+// ConstraintFrequency gibt an, wie oft ein Constraint (als Quelltext) in einem Repository verwendet wird
+type ConstraintFrequency struct {
+	Constraint string `json:"constraint"`
+	Kind       string `json:"kind"`
+	Count      int    `json:"count"`
+}
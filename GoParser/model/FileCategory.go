@@ -0,0 +1,14 @@
+package model
+
+// Kategorien von Quelldateien. Jede Datei gehört genau einer Kategorie an, die Zähler aller
+// Kategorien ergeben zusammen die Zähler des gesamten Repositories.
+const (
+	FileCategoryProduction = "production"
+	FileCategoryTest       = "test"      // _test.go und Dateien unter testdata/
+	FileCategoryGenerated  = "generated" // Kopfzeile "// Code generated ... DO NOT EDIT."
+	FileCategoryVendored   = "vendored"  // unter vendor/ oder third_party/
+	FileCategoryExample    = "example"   // unter example/, examples/ bzw. _example/, _examples/
+)
+
+// FileCategories enthält alle Kategorien in der Reihenfolge der Ausgabe
+var FileCategories = []string{FileCategoryProduction, FileCategoryTest, FileCategoryGenerated, FileCategoryVendored, FileCategoryExample}
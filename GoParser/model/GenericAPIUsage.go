@@ -0,0 +1,8 @@
+package model
+
+// GenericAPIUsage beschreibt, wie ein Projekt generische APIs der Standardbibliothek und von
+// golang.org/x/exp verwendet, also Generics als Konsument statt als Autor nutzt
+type GenericAPIUsage struct {
+	Imports map[string]int            `json:"imports"` // Importpfad -> Anzahl Dateien, die das Paket importieren
+	Uses    map[string]map[string]int `json:"uses"`    // Importpfad -> Bezeichner -> Anzahl Verwendungsstellen
+}
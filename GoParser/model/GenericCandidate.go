@@ -0,0 +1,17 @@
+package model
+
+// Arten von Stellen, die Generics verwenden könnten, aber nicht verwenden
+const (
+	CandidateKindAnyTypeSwitch = "any_type_switch" // Funktion mit any-Parameter, die sofort nach dem Typ unterscheidet
+	CandidateKindDuplicateFunc = "duplicate_func"  // Funktion einer Familie, die sich nur im Elementtyp unterscheidet
+	CandidateKindSortInterface = "sort_interface"  // Typ mit Len, Less und Swap für sort.Interface
+)
+
+// GenericCandidate beschreibt eine einzelne Stelle, die ein Kandidat für Generics ist
+type GenericCandidate struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	Detail string `json:"detail"` // Parametername bzw. alle Funktionen der Familie
+}
@@ -2,8 +2,12 @@ package model
 
 type GenericCounters struct {
 	// Funktionen
-	FuncTotal   int `json:"func_total"`
-	FuncGeneric int `json:"func_generic"`
+	FuncTotal                  int `json:"func_total"`
+	FuncGeneric                int `json:"func_generic"`
+	FuncGenericTrivialBound    int `json:"func_generic_trivial_bound"`     // nur any bzw. interface{}
+	FuncGenericNonTrivialBound int `json:"func_generic_non_trivial_bound"` // Trivial und NonTrivial ergeben zusammen FuncGeneric
+	FuncGenericInlineUnion     int `json:"func_generic_inline_union"`      // z.B. [T ~int | ~string]
+	FuncGenericStructBound     int `json:"func_generic_struct_bound"`
 
 	// Methoden
 	MethodTotal                                  int `json:"method_total"`
@@ -19,6 +23,61 @@ type GenericCounters struct {
 
 	// Sonstiges
 	TypeDecl        int `json:"type_decl"`
-	GenericTypeDecl int `json:"generic_type_decl"`
-	GenericTypeSet  int `json:"generic_type_set"`
+	GenericTypeDecl int `json:"generic_type_decl"` // ohne generische Aliase
+	GenericTypeSet  int `json:"generic_type_set"`  // benannte Interfaces mit Typ-Termen
+
+	// Type Sets (benannte Interfaces und Inline-Constraints; Tilde- und exakte Terme ergeben zusammen alle Terme)
+	TypeSetInline       int `json:"type_set_inline"` // Inline-Constraints mit Termen, z.B. [T ~int | ~string]
+	TypeSetTerms        int `json:"type_set_terms"`
+	TypeSetTildeTerms   int `json:"type_set_tilde_terms"`
+	TypeSetExactTerms   int `json:"type_set_exact_terms"`
+	TypeSetEmbedded     int `json:"type_set_embedded"`     // Type Sets, die Constraint-Interfaces einbetten
+	TypeSetIntersection int `json:"type_set_intersection"` // Type Sets mit mehreren einschränkenden Elementen
+	TypeSetMixed        int `json:"type_set_mixed"`        // Type Sets mit zusätzlichen Methoden
+
+	// Formen generischer Typdefinitionen neben Structs (ohne Aliase)
+	InterfaceTotal                      int `json:"interface_total"`
+	InterfaceGeneric                    int `json:"interface_generic"`
+	InterfaceGenericMethodUsesTypeParam int `json:"interface_generic_method_uses_type_param"` // mindestens eine Methode verwendet einen Typparameter
+	FuncTypeGeneric                     int `json:"func_type_generic"`
+	MapTypeGeneric                      int `json:"map_type_generic"`
+	SliceTypeGeneric                    int `json:"slice_type_generic"` // Slices und Arrays
+	ChanTypeGeneric                     int `json:"chan_type_generic"`
+
+	// Aliase (type A = B); generische Aliase gibt es seit Go 1.24
+	AliasTotal                 int `json:"alias_total"`
+	AliasGeneric               int `json:"alias_generic"`
+	AliasOfInstantiatedGeneric int `json:"alias_of_instantiated_generic"` // z.B. type IntList = List[int]
+
+	// Instanziierungen (explizit/inferiert bzw. gleiches/anderes Paket ergeben jeweils die Gesamtanzahl)
+	InstantiationExplicit     int `json:"instantiation_explicit"`
+	InstantiationInferred     int `json:"instantiation_inferred"` // nur im go/types-Modus
+	InstantiationSamePackage  int `json:"instantiation_same_package"`
+	InstantiationCrossPackage int `json:"instantiation_cross_package"`
+
+	// Constraint-Katalog: Art des Constraints je Typparameter (alle Arten ergeben zusammen die Anzahl der Typparameter)
+	ConstraintAny              int `json:"constraint_any"`
+	ConstraintComparable       int `json:"constraint_comparable"`
+	ConstraintOrdered          int `json:"constraint_ordered"`
+	ConstraintInlineUnion      int `json:"constraint_inline_union"`
+	ConstraintMethodInterface  int `json:"constraint_method_interface"`
+	ConstraintTypeSetInterface int `json:"constraint_type_set_interface"`
+	ConstraintSelfReferential  int `json:"constraint_self_referential"`
+	ConstraintConcrete         int `json:"constraint_concrete"`
+	ConstraintOther            int `json:"constraint_other"`
+
+	// Kandidaten für Generics: Code, der Generics verwenden könnte, es aber nicht tut
+	CandidateAnyTypeSwitch   int `json:"candidate_any_type_switch"`  // Funktionen mit any-Parameter und sofortigem Type Switch bzw. Type Assertion
+	CandidateDuplicateFunc   int `json:"candidate_duplicate_func"`   // Funktionen, die zu einer Familie gehören
+	CandidateDuplicateFamily int `json:"candidate_duplicate_family"` // Familien gleicher Funktionen mit unterschiedlichen Typen
+	CandidateSortInterface   int `json:"candidate_sort_interface"`   // Typen, die sort.Interface implementieren
+
+	// Verwendung generischer APIs aus Standardbibliothek und golang.org/x/exp (slices, maps, cmp, ...)
+	GenericAPIImports int `json:"generic_api_imports"` // Importe solcher Pakete, je Datei gezählt
+	GenericAPIUses    int `json:"generic_api_uses"`    // Verwendungsstellen, z.B. slices.Contains(...) oder atomic.Pointer[T]
+
+	// Iteratoren (range over func, Go 1.23)
+	IterSeqFunc   int `json:"iter_seq_func"`   // Funktionen und Methoden, die iter.Seq, iter.Seq2 oder func(yield func(...) bool) zurückgeben
+	RangeOverFunc int `json:"range_over_func"` // for ... range über einen Funktionswert
+	IterPull      int `json:"iter_pull"`       // Verwendungen von iter.Pull und iter.Pull2
 }
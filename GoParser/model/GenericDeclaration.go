@@ -0,0 +1,23 @@
+package model
+
+// Arten von generischen Deklarationen
+const (
+	DeclarationKindFunc      = "func"
+	DeclarationKindStruct    = "struct"
+	DeclarationKindInterface = "interface"
+	DeclarationKindAlias     = "alias"
+	DeclarationKindType      = "type" // sonstige benannte Typen (map, slice, func, ...)
+)
+
+// GenericDeclaration beschreibt eine einzelne generische Funktion oder Typ-Deklaration
+type GenericDeclaration struct {
+	File            string   `json:"file"`
+	Line            int      `json:"line"`
+	Name            string   `json:"name"`
+	Kind            string   `json:"kind"`
+	TypeParams      []string `json:"type_params"`
+	Constraints     []string `json:"constraints"`       // Quelltext des Constraints je Typparameter
+	ConstraintKinds []string `json:"constraint_kinds"`  // Art des Constraints je Typparameter (ConstraintKind*)
+	TypeParamUsages []string `json:"type_param_usages"` // Verwendungsorte je Typparameter, kommagetrennt (TypeParamUsage*)
+	NonTrivial      bool     `json:"non_trivial"`       // mindestens ein Typparameter hat einen non-trivial Bound
+}
@@ -0,0 +1,16 @@
+package model
+
+// GoDirectives sind die Angaben zur Go-Version aus einer go.mod- oder go.work-Datei
+type GoDirectives struct {
+	ModulePath string // module-Direktive, leer bei go.work
+	GoVersion  string // go-Direktive, z.B. "1.21" oder "1.22.0"; leer, wenn keine angegeben ist
+	Toolchain  string // toolchain-Direktive, z.B. "go1.22.3"
+}
+
+// GoVersionAdoption ist die Verbreitung von Generics unter den Modulen mit derselben go-Direktive
+type GoVersionAdoption struct {
+	GoVersion string // z.B. "1.21", leer für Module ohne go-Direktive
+	Modules   int
+	Declaring int // Module mit eigenen generischen Funktionen oder Typen
+	Using     int // Module, die Generics instanziieren oder generische APIs verwenden
+}
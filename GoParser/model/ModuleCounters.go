@@ -0,0 +1,10 @@
+package model
+
+// ModuleCounters sind die Zähler eines Moduls innerhalb eines Repositories. Zu einem Modul gehören
+// alle Pakete unterhalb seiner go.mod-Datei, die nicht in einem tiefer liegenden Modul liegen
+// (z.B. die Staging-Module von kubernetes). Pakete außerhalb jedes Moduls haben Dir "".
+type ModuleCounters struct {
+	Dir string // Verzeichnis der go.mod relativ zum Repository, "." für das Wurzelverzeichnis
+	GoDirectives
+	Counters GenericCounters
+}
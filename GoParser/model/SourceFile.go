@@ -0,0 +1,10 @@
+package model
+
+// SourceFile ist eine einzelne Datei eines analysierten Projekts.
+// Path ist relativ zur Wurzel des Projekts und verwendet immer '/' als Trenner.
+// Category ist eine der FileCategory*-Konstanten; leer, wenn die Datei noch nicht eingeordnet wurde.
+type SourceFile struct {
+	Path     string
+	Content  string
+	Category string
+}
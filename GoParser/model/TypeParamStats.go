@@ -0,0 +1,31 @@
+package model
+
+// Klassen des Histogramms über die Anzahl der Typparameter einer Deklaration
+const (
+	TypeParamArity1     = "1"
+	TypeParamArity2     = "2"
+	TypeParamArity3     = "3"
+	TypeParamArity4Plus = "4+"
+)
+
+// TypeParamArities enthält alle Klassen in der Reihenfolge der Ausgabe
+var TypeParamArities = []string{TypeParamArity1, TypeParamArity2, TypeParamArity3, TypeParamArity4Plus}
+
+// Verwendungsorte eines Typparameters. Ein Typparameter kann an mehreren Orten verwendet werden,
+// nur TypeParamUsageUnused schließt alle anderen aus.
+const (
+	TypeParamUsageParams     = "params"     // Parameter einer Funktion
+	TypeParamUsageResults    = "results"    // Ergebnisse einer Funktion
+	TypeParamUsageBody       = "body"       // Funktionsrumpf bzw. Typdefinition
+	TypeParamUsageConstraint = "constraint" // Constraint eines anderen Typparameters, z.B. E in [S ~[]E, E any]
+	TypeParamUsageUnused     = "unused"
+)
+
+// TypeParamUsages enthält alle Verwendungsorte in der Reihenfolge der Ausgabe
+var TypeParamUsages = []string{TypeParamUsageParams, TypeParamUsageResults, TypeParamUsageBody, TypeParamUsageConstraint, TypeParamUsageUnused}
+
+// TypeParamStats enthält Verteilungen über die Typparameter der generischen Deklarationen
+type TypeParamStats struct {
+	Arity map[string]int `json:"arity"` // Deklarationen je Anzahl Typparameter (TypeParamArity*)
+	Usage map[string]int `json:"usage"` // Typparameter je Verwendungsort (TypeParamUsage*)
+}
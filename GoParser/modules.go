@@ -0,0 +1,90 @@
+package main
+
+import (
+	"GoParser/model"
+	"GoParser/utils"
+	"path"
+	"strings"
+)
+
+// moduleRoots holds the modules of a source tree by the directory of their go.mod file
+type moduleRoots map[string]model.ModuleCounters
+
+// add records the module of a go.mod file. Like for the go command, go.mod files
+// below testdata, vendor and directories starting with _ or . do not start a module.
+func (roots moduleRoots) add(goMod model.SourceFile) {
+	dir := path.Dir(goMod.Path)
+	if path.Base(goMod.Path) != "go.mod" || isIgnoredModuleDir(dir) {
+		return
+	}
+	roots[dir] = model.ModuleCounters{Dir: dir, GoDirectives: goDirectives(goMod)}
+}
+
+func isIgnoredModuleDir(dir string) bool {
+	for _, element := range strings.Split(dir, "/") {
+		if element == "testdata" || element == "vendor" || (element != "." && (strings.HasPrefix(element, "_") || strings.HasPrefix(element, "."))) {
+			return true
+		}
+	}
+	return false
+}
+
+// moduleOf returns the innermost module containing the package directory dir,
+// or a module with an empty Dir if dir does not belong to any module
+func (roots moduleRoots) moduleOf(dir string) model.ModuleCounters {
+	for {
+		if module, ok := roots[dir]; ok {
+			return module
+		}
+		if dir == "." || dir == "/" || dir == "" {
+			return model.ModuleCounters{}
+		}
+		dir = path.Dir(dir)
+	}
+}
+
+// addModuleCounters adds counters to the counters of a module of the result
+func addModuleCounters(result *model.AnalysisResult, module model.ModuleCounters, counters model.GenericCounters) {
+	if result.ByModule == nil {
+		result.ByModule = make(map[string]model.ModuleCounters)
+	}
+	moduleCounters, ok := result.ByModule[module.Dir]
+	if !ok {
+		moduleCounters = module
+		moduleCounters.Counters = model.GenericCounters{}
+	}
+	aggregateCounters(&moduleCounters.Counters, counters)
+	result.ByModule[module.Dir] = moduleCounters
+}
+
+// goDirectives reads the module, go and toolchain directives of a go.mod or go.work file.
+// Files that cannot be parsed are treated like files without these directives.
+func goDirectives(file model.SourceFile) model.GoDirectives {
+	parse := utils.ParseGoMod
+	if path.Base(file.Path) == "go.work" {
+		parse = utils.ParseGoWork
+	}
+	modFile, err := parse(file.Content)
+	if err != nil {
+		return model.GoDirectives{}
+	}
+	return model.GoDirectives{ModulePath: modFile.Module, GoVersion: modFile.GoVersion, Toolchain: modFile.Toolchain}
+}
+
+// repositoryGoDirectives returns the Go version the repository as a whole is built with: the go.work
+// of the root directory (workspace, nil if there is none), otherwise the root module or, if the
+// repository has exactly one module, that one
+func repositoryGoDirectives(workspace *model.GoDirectives, modules moduleRoots) model.GoDirectives {
+	if workspace != nil {
+		return *workspace
+	}
+	if root, ok := modules["."]; ok {
+		return root.GoDirectives
+	}
+	if len(modules) == 1 {
+		for _, module := range modules {
+			return module.GoDirectives
+		}
+	}
+	return model.GoDirectives{}
+}
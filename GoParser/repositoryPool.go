@@ -0,0 +1,98 @@
+package main
+
+import (
+	"GoParser/model"
+	"GoParser/utils"
+	"sync"
+)
+
+// repositoryJob is a single repository of the input list
+type repositoryJob struct {
+	index int
+	utils.RepositoryEntry
+}
+
+// repositoryResult is the outcome of downloading and analysing one repository.
+// fetchErr is set if the repository could not be downloaded, analyzeErr if some files could not be analysed.
+// commit is the SHA of the analysed commit.
+type repositoryResult struct {
+	job        repositoryJob
+	commit     string
+	result     model.AnalysisResult
+	fetchErr   error
+	analyzeErr error
+}
+
+// analyzeRepositories downloads and analyses the repositories with a bounded number of workers.
+// Every worker gets its own analyzer, because analyzers are not safe for concurrent use.
+// handle is called from the calling goroutine for every repository in the order of the
+// input list, so output and database writes stay deterministic and serialized.
+// At most 2×workers repositories are dispatched but not yet handled; a slow repository early in the
+// list therefore pauses the workers instead of letting finished results pile up in memory.
+func analyzeRepositories(
+	repositories []utils.RepositoryEntry,
+	workers int,
+	fetch func(utils.RepositoryEntry) (utils.SourceTree, string, error),
+	newAnalyzer func() ASTAnalyzer,
+	handle func(repositoryResult),
+) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan repositoryJob)
+	results := make(chan repositoryResult, workers)
+	window := make(chan struct{}, 2*workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			analyzer := newAnalyzer()
+			for job := range jobs {
+				results <- analyzeRepository(job, fetch, analyzer)
+			}
+		}()
+	}
+
+	go func() {
+		for i, repository := range repositories {
+			// Ein Platz im Fenster wird erst frei, wenn ein Ergebnis an handle übergeben wurde
+			window <- struct{}{}
+			jobs <- repositoryJob{index: i, RepositoryEntry: repository}
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	// Ergebnisse, die vor ihren Vorgängern fertig werden, bis zu ihrer Reihe zurückhalten
+	pending := make(map[int]repositoryResult)
+	next := 0
+	for result := range results {
+		pending[result.job.index] = result
+		for {
+			ready, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			handle(ready)
+			<-window
+			next++
+		}
+	}
+}
+
+func analyzeRepository(job repositoryJob, fetch func(utils.RepositoryEntry) (utils.SourceTree, string, error), analyzer ASTAnalyzer) repositoryResult {
+	tree, commit, err := fetch(job.RepositoryEntry)
+	if err != nil {
+		return repositoryResult{job: job, fetchErr: err}
+	}
+	// Schließen löscht temporäre Archive, sobald das Repository analysiert ist
+	defer tree.Close()
+
+	result, err := analyzer.AnalyzeTree(tree)
+	return repositoryResult{job: job, commit: commit, result: result, analyzeErr: err}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"GoParser/model"
+	"GoParser/utils"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeRepositoriesKeepsInputOrder(t *testing.T) {
+	var repositories []utils.RepositoryEntry
+	for i := 0; i < 20; i++ {
+		repositories = append(repositories, utils.RepositoryEntry{Owner: "owner", Repo: fmt.Sprintf("repo%d", i)})
+	}
+
+	var inFlight, maxInFlight int32
+	fetch := func(entry utils.RepositoryEntry) (utils.SourceTree, string, error) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+
+		// Frühere Repositories brauchen länger, damit die Ergebnisse außer der Reihe fertig werden
+		var index int
+		fmt.Sscanf(entry.Repo, "repo%d", &index)
+		time.Sleep(time.Duration(20-index) * time.Millisecond)
+
+		if index == 3 {
+			return nil, "", errors.New("download failed")
+		}
+		return utils.NewMemoryTree([]model.SourceFile{{Path: "a.go", Content: "package a\n\nfunc F[T any]() {}\n"}}), "abc123", nil
+	}
+
+	var handled []string
+	analyzeRepositories(repositories, 4, fetch, NewASTAnalyzer, func(r repositoryResult) {
+		handled = append(handled, r.job.Repo)
+		if r.job.Repo == "repo3" && r.fetchErr == nil {
+			t.Errorf("expected fetch error for repo3")
+		}
+		if r.job.Repo != "repo3" && r.result.Counters.FuncGeneric != 1 {
+			t.Errorf("FuncGeneric of %s = %d, want 1", r.job.Repo, r.result.Counters.FuncGeneric)
+		}
+	})
+
+	if len(handled) != len(repositories) {
+		t.Fatalf("handled %d repositories, want %d", len(handled), len(repositories))
+	}
+	for i, repo := range handled {
+		if repo != repositories[i].Repo {
+			t.Fatalf("handled %s at position %d, want %s", repo, i, repositories[i].Repo)
+		}
+	}
+	if maxInFlight > 4 {
+		t.Errorf("%d downloads in parallel, want at most 4", maxInFlight)
+	}
+}
+
+func TestAnalyzeRepositoriesBoundsPendingResults(t *testing.T) {
+	var repositories []utils.RepositoryEntry
+	for i := 0; i < 30; i++ {
+		repositories = append(repositories, utils.RepositoryEntry{Owner: "owner", Repo: fmt.Sprintf("repo%d", i)})
+	}
+
+	var started int32
+	fetch := func(entry utils.RepositoryEntry) (utils.SourceTree, string, error) {
+		atomic.AddInt32(&started, 1)
+		// Das erste Repository braucht lange, alle anderen sind sofort fertig
+		if entry.Repo == "repo0" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		return utils.NewMemoryTree(nil), "abc123", nil
+	}
+
+	var startedBeforeFirst int32
+	analyzeRepositories(repositories, 2, fetch, NewASTAnalyzer, func(r repositoryResult) {
+		if r.job.Repo == "repo0" {
+			startedBeforeFirst = atomic.LoadInt32(&started)
+		}
+	})
+
+	if startedBeforeFirst > 4 {
+		t.Errorf("%d repositories started while the first one was analysed, want at most 4", startedBeforeFirst)
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"GoParser/model"
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// typeSetInfo describes the elements of an interface or inline constraint that restrict its type set.
+// Every element of an interface intersects the type set, a union (|) forms one element.
+type typeSetInfo struct {
+	tildeTerms   int // ~int
+	exactTerms   int // int, MyStruct
+	embeddedSets int // eingebettete Constraint-Interfaces mit Termen, z.B. Number oder Signed | Unsigned
+	termElements int // Elemente, die die Typmenge einschränken
+	methods      int
+}
+
+func (info typeSetInfo) isTypeSet() bool {
+	return info.termElements > 0
+}
+
+// elementKind is the meaning of a type expression used as interface element or union term
+type elementKind int
+
+const (
+	elementUnknown          elementKind = iota // nicht auflösbar, z.B. importiert ohne go/types
+	elementType                                // konkreter Typ, also ein exakter Term
+	elementMethodInterface                     // Interface ohne Terme: Einbettung erweitert nur die Methodenmenge
+	elementTypeSetInterface                    // Interface mit Termen: Einbettung schränkt die Typmenge ein
+)
+
+// typeSetAnalyzer resolves interface elements within a package. seen guards against cyclic declarations.
+type typeSetAnalyzer struct {
+	imports map[string]string
+	pkgInfo *packageInfo
+	seen    map[*ast.TypeSpec]bool
+}
+
+func newTypeSetAnalyzer(imports map[string]string, pkgInfo *packageInfo) *typeSetAnalyzer {
+	return &typeSetAnalyzer{imports: imports, pkgInfo: pkgInfo, seen: make(map[*ast.TypeSpec]bool)}
+}
+
+// analyzeInterface collects the type set elements and methods of an interface
+func (a *typeSetAnalyzer) analyzeInterface(iface *ast.InterfaceType) typeSetInfo {
+	info := typeSetInfo{}
+	if iface.Methods == nil {
+		return info
+	}
+	for _, field := range iface.Methods.List {
+		if len(field.Names) > 0 {
+			info.methods++
+			continue
+		}
+		a.analyzeElement(field.Type, &info)
+	}
+	return info
+}
+
+// analyzeConstraint collects the type set of an inline constraint like [T ~int | ~string] or
+// [T interface{ ~int; String() string }]. ok is false for named and concrete constraints.
+func (a *typeSetAnalyzer) analyzeConstraint(constraint ast.Expr) (info typeSetInfo, ok bool) {
+	switch c := constraint.(type) {
+	case *ast.ParenExpr:
+		return a.analyzeConstraint(c.X)
+	case *ast.InterfaceType:
+		return a.analyzeInterface(c), true
+	case *ast.BinaryExpr, *ast.UnaryExpr:
+		a.analyzeElement(c, &info)
+		return info, true
+	}
+	return typeSetInfo{}, false
+}
+
+// analyzeElement adds one embedded element of an interface to info
+func (a *typeSetAnalyzer) analyzeElement(expr ast.Expr, info *typeSetInfo) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		a.analyzeElement(e.X, info)
+
+	case *ast.BinaryExpr:
+		if e.Op != token.OR {
+			return
+		}
+		info.termElements++
+		for _, term := range unionTerms(e) {
+			if unary, ok := term.(*ast.UnaryExpr); ok && unary.Op == token.TILDE {
+				info.tildeTerms++
+				continue
+			}
+			switch a.resolveElement(term) {
+			case elementMethodInterface, elementTypeSetInterface:
+				info.embeddedSets++
+			default:
+				info.exactTerms++
+			}
+		}
+
+	case *ast.UnaryExpr:
+		if e.Op == token.TILDE {
+			info.termElements++
+			info.tildeTerms++
+		}
+
+	default:
+		switch a.resolveElement(expr) {
+		case elementTypeSetInterface:
+			info.termElements++
+			info.embeddedSets++
+		case elementType:
+			info.termElements++
+			info.exactTerms++
+		}
+	}
+}
+
+// resolveElement determines whether a type expression denotes an interface (with or without terms) or a type
+func (a *typeSetAnalyzer) resolveElement(expr ast.Expr) elementKind {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return a.resolveElement(e.X)
+
+	case *ast.Ident:
+		if ts := resolveTypeSpec(e, a.pkgInfo.typeSpecs); ts != nil {
+			return a.resolveTypeSpec(ts)
+		}
+		switch {
+		case e.Name == "any" || e.Name == "comparable":
+			return elementMethodInterface
+		case predeclaredTypes[e.Name]:
+			return elementType
+		}
+		return a.resolveWithTypes(e)
+
+	case *ast.SelectorExpr:
+		if pkg, ok := e.X.(*ast.Ident); ok && isKnownTypeSetConstraint(a.imports[pkg.Name], e.Sel.Name) {
+			return elementTypeSetInterface
+		}
+		return a.resolveWithTypes(e.Sel)
+
+	case *ast.IndexExpr:
+		// Instanziierte Constraints wie Number[T] haben die Form ihrer Deklaration
+		return a.resolveElement(e.X)
+	case *ast.IndexListExpr:
+		return a.resolveElement(e.X)
+
+	case *ast.InterfaceType:
+		if a.analyzeInterface(e).isTypeSet() {
+			return elementTypeSetInterface
+		}
+		return elementMethodInterface
+
+	case *ast.StructType, *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.StarExpr:
+		return elementType
+	}
+	return elementUnknown
+}
+
+func (a *typeSetAnalyzer) resolveTypeSpec(ts *ast.TypeSpec) elementKind {
+	if a.seen[ts] {
+		return elementUnknown
+	}
+	a.seen[ts] = true
+	defer delete(a.seen, ts)
+
+	if ts.Assign.IsValid() {
+		return a.resolveElement(ts.Type)
+	}
+	if iface, ok := ts.Type.(*ast.InterfaceType); ok {
+		if a.analyzeInterface(iface).isTypeSet() {
+			return elementTypeSetInterface
+		}
+		return elementMethodInterface
+	}
+	return elementType
+}
+
+// resolveWithTypes resolves an identifier of another package with go/types, if available
+func (a *typeSetAnalyzer) resolveWithTypes(ident *ast.Ident) elementKind {
+	if a.pkgInfo.typesInfo == nil {
+		return elementUnknown
+	}
+	typeName, ok := a.pkgInfo.typesInfo.Uses[ident].(*types.TypeName)
+	if !ok {
+		return elementUnknown
+	}
+	iface, ok := typeName.Type().Underlying().(*types.Interface)
+	switch {
+	case !ok:
+		return elementType
+	case iface.IsMethodSet():
+		return elementMethodInterface
+	}
+	return elementTypeSetInterface
+}
+
+// unionTerms flattens a union A | B | C into its terms
+func unionTerms(expr ast.Expr) []ast.Expr {
+	if binary, ok := expr.(*ast.BinaryExpr); ok && binary.Op == token.OR {
+		return append(unionTerms(binary.X), unionTerms(binary.Y)...)
+	}
+	if paren, ok := expr.(*ast.ParenExpr); ok {
+		return unionTerms(paren.X)
+	}
+	return []ast.Expr{expr}
+}
+
+// countTypeSet adds a type set (named interface or inline constraint) to the counters
+func countTypeSet(info typeSetInfo, counters *model.GenericCounters) {
+	counters.TypeSetTerms += info.tildeTerms + info.exactTerms
+	counters.TypeSetTildeTerms += info.tildeTerms
+	counters.TypeSetExactTerms += info.exactTerms
+	if info.embeddedSets > 0 {
+		counters.TypeSetEmbedded++
+	}
+	if info.termElements > 1 {
+		counters.TypeSetIntersection++
+	}
+	if info.methods > 0 {
+		counters.TypeSetMixed++
+	}
+}
+
+// countInlineTypeSets counts the inline type set constraints of a type parameter list
+func countInlineTypeSets(typeParams *ast.FieldList, analyzer *typeSetAnalyzer, counters *model.GenericCounters) {
+	if typeParams == nil {
+		return
+	}
+	for _, field := range typeParams.List {
+		if info, ok := analyzer.analyzeConstraint(field.Type); ok && info.isTypeSet() {
+			counters.TypeSetInline++
+			countTypeSet(info, counters)
+		}
+	}
+}
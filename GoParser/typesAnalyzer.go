@@ -0,0 +1,156 @@
+package main
+
+import (
+	"GoParser/model"
+	"GoParser/utils"
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// typesAnalyzerImpl classifies type parameters from their real type sets using go/types.
+// The files of every package are type-checked against the rest of the source tree,
+// the standard library and the local module cache. Constraints that cannot be resolved
+// (e.g. because a dependency is missing in the module cache) fall back to the
+// syntactic classification of astAnalyzerImpl.
+type typesAnalyzerImpl struct {
+	deps *dependencyCache
+}
+
+func NewTypesAnalyzer(modCache string) ASTAnalyzer {
+	if modCache == "" {
+		modCache = utils.DefaultModCache()
+	}
+	return &typesAnalyzerImpl{deps: newDependencyCache(modCache)}
+}
+
+func (a *typesAnalyzerImpl) AnalyzeFile(src string) (model.AnalysisResult, error) {
+	return a.AnalyzeFiles([]model.SourceFile{{Path: "main.go", Content: src}})
+}
+
+func (a *typesAnalyzerImpl) AnalyzeFiles(files []model.SourceFile) (model.AnalysisResult, error) {
+	return a.AnalyzeTree(utils.NewMemoryTree(files))
+}
+
+// AnalyzeTree streams the packages of the tree like astAnalyzerImpl. Packages of the tree that
+// are imported are read again from the tree when they are needed; only their type information is kept.
+func (a *typesAnalyzerImpl) AnalyzeTree(tree utils.SourceTree) (model.AnalysisResult, error) {
+	importer := newSourceTreeImporter(token.NewFileSet(), a.deps, tree)
+	return analyzeTree(tree, func(fset *token.FileSet, pkg *parsedPackage) (model.AnalysisResult, error) {
+		pkgInfo := collectPackageInfo(pkg.files)
+		pkgInfo.typesPkg, pkgInfo.typesInfo = checkPackage(fset, pkg, importer)
+		for name, info := range collectTypeBoundsInfoFromTypes(pkg.files, pkgInfo.typesInfo) {
+			pkgInfo.typeBoundsInfo[name] = info
+		}
+		return analyzePackage(fset, pkg.files, pkg.categories, pkgInfo)
+	})
+}
+
+// checkPackage type-checks all files of a package. Errors are expected for incomplete
+// trees (e.g. missing dependencies); the recorded information is still usable.
+func checkPackage(fset *token.FileSet, pkg *parsedPackage, importer *sourceTreeImporter) (*types.Package, *types.Info) {
+	info := &types.Info{
+		Defs:      make(map[*ast.Ident]types.Object),
+		Uses:      make(map[*ast.Ident]types.Object),
+		Instances: make(map[*ast.Ident]types.Instance),
+	}
+	conf := types.Config{
+		Importer:    importer,
+		FakeImportC: true,
+		Error:       func(error) {},
+	}
+	typesPkg, _ := conf.Check(importer.importPathFor(pkg.dir), fset, pkg.files, info)
+	return typesPkg, info
+}
+
+// collectTypeBoundsInfoFromTypes classifies the type parameters of every generic type
+// declaration. Types with a constraint that could not be resolved are left out,
+// so the caller keeps their syntactic classification.
+func collectTypeBoundsInfoFromTypes(files []*ast.File, info *types.Info) map[string]TypeBoundInfo {
+	typeBoundsInfo := make(map[string]TypeBoundInfo)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec, ok := spec.(*ast.TypeSpec)
+				if !ok || typeSpec.TypeParams == nil || len(typeSpec.TypeParams.List) == 0 {
+					continue
+				}
+				typeName, ok := info.Defs[typeSpec.Name].(*types.TypeName)
+				if !ok {
+					continue
+				}
+				if boundInfo, ok := classifyTypeParams(typeParamsOf(typeName.Type())); ok {
+					typeBoundsInfo[typeSpec.Name.Name] = boundInfo
+				}
+			}
+		}
+	}
+
+	return typeBoundsInfo
+}
+
+func typeParamsOf(t types.Type) *types.TypeParamList {
+	switch t := t.(type) {
+	case *types.Named:
+		return t.TypeParams()
+	case *types.Alias:
+		return t.TypeParams()
+	}
+	return nil
+}
+
+// classifyTypeParams reports whether any type parameter has a non-trivial constraint
+// or a struct in its type set. ok is false if a constraint is invalid.
+func classifyTypeParams(typeParams *types.TypeParamList) (info TypeBoundInfo, ok bool) {
+	if typeParams == nil {
+		return TypeBoundInfo{}, false
+	}
+	for i := 0; i < typeParams.Len(); i++ {
+		iface, isIface := typeParams.At(i).Constraint().Underlying().(*types.Interface)
+		if !isIface {
+			return TypeBoundInfo{}, false
+		}
+		// Empty() is only true for an empty type set without methods and without comparable
+		if !iface.Empty() {
+			info.hasNonTrivialBound = true
+		}
+		if typeSetContainsStruct(iface, make(map[*types.Interface]bool)) {
+			info.hasStructBound = true
+		}
+	}
+	return info, true
+}
+
+// typeSetContainsStruct reports whether a term of the interface's type set is a struct type.
+// Implicit interfaces like the constraint in [T MyStruct] have the struct as embedded type.
+func typeSetContainsStruct(iface *types.Interface, seen map[*types.Interface]bool) bool {
+	if seen[iface] {
+		return false
+	}
+	seen[iface] = true
+
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		switch embedded := iface.EmbeddedType(i).(type) {
+		case *types.Union:
+			for j := 0; j < embedded.Len(); j++ {
+				if _, ok := embedded.Term(j).Type().Underlying().(*types.Struct); ok {
+					return true
+				}
+			}
+		default:
+			switch underlying := embedded.Underlying().(type) {
+			case *types.Struct:
+				return true
+			case *types.Interface:
+				if typeSetContainsStruct(underlying, seen) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
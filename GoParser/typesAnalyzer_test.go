@@ -0,0 +1,230 @@
+package main
+
+import (
+	"GoParser/model"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTypesAnalyzerClassifiesImportedConstraints(t *testing.T) {
+	files := []model.SourceFile{
+		{Path: "go.mod", Content: "module example.com/tree\n\ngo 1.22\n"},
+		{Path: "constraints/constraints.go", Content: `package constraints
+
+type Anything interface{}
+
+type Point struct {
+	X, Y int
+}
+`},
+		{Path: "box/box.go", Content: `package box
+
+import (
+	"example.com/tree/constraints"
+	"fmt"
+)
+
+type Loose[T constraints.Anything] struct{ value T }
+
+type Pinned[T constraints.Point] struct{ value T }
+
+type Printable[T fmt.Stringer] struct{ value T }
+
+func (l Loose[T]) Get() T { return l.value }
+
+func (p Printable[T]) Get() T { return p.value }
+
+func Keep[T constraints.Anything](v T) T { return v }
+
+func Move[T constraints.Point](p T) T { return p }
+`},
+	}
+
+	result, err := NewTypesAnalyzer("").AnalyzeFiles(files)
+	counters := result.Counters
+	if err != nil {
+		t.Fatalf("failed to analyze files: %v", err)
+	}
+
+	if counters.StructGenericBound != 2 {
+		t.Errorf("StructGenericBound = %d, want 2", counters.StructGenericBound)
+	}
+	if counters.StructAsTypeBound != 1 {
+		t.Errorf("StructAsTypeBound = %d, want 1", counters.StructAsTypeBound)
+	}
+	if counters.MethodWithGenericReceiverTrivialTypeBound != 1 {
+		t.Errorf("MethodWithGenericReceiverTrivialTypeBound = %d, want 1", counters.MethodWithGenericReceiverTrivialTypeBound)
+	}
+	if counters.MethodWithGenericReceiverNonTrivialTypeBound != 1 {
+		t.Errorf("MethodWithGenericReceiverNonTrivialTypeBound = %d, want 1", counters.MethodWithGenericReceiverNonTrivialTypeBound)
+	}
+	if counters.FuncGenericTrivialBound != 1 || counters.FuncGenericNonTrivialBound != 1 {
+		t.Errorf("FuncGenericTrivialBound/NonTrivialBound = %d/%d, want 1/1", counters.FuncGenericTrivialBound, counters.FuncGenericNonTrivialBound)
+	}
+	if counters.FuncGenericStructBound != 1 {
+		t.Errorf("FuncGenericStructBound = %d, want 1", counters.FuncGenericStructBound)
+	}
+}
+
+func TestTypesAnalyzerCountsInferredInstantiations(t *testing.T) {
+	files := []model.SourceFile{
+		{Path: "a.go", Content: `package a
+
+import "slices"
+
+func Max[T int | float64](a, b T) T {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func use(values []int) {
+	_ = Max(1, 2)
+	_ = Max[float64](1, 2)
+	_ = slices.Contains(values, 3)
+}
+`},
+	}
+
+	result, err := NewTypesAnalyzer("").AnalyzeFiles(files)
+	counters := result.Counters
+	if err != nil {
+		t.Fatalf("failed to analyze files: %v", err)
+	}
+
+	if counters.InstantiationExplicit != 1 {
+		t.Errorf("InstantiationExplicit = %d, want 1", counters.InstantiationExplicit)
+	}
+	if counters.InstantiationInferred != 2 {
+		t.Errorf("InstantiationInferred = %d, want 2", counters.InstantiationInferred)
+	}
+	if counters.InstantiationCrossPackage != 1 {
+		t.Errorf("InstantiationCrossPackage = %d, want 1", counters.InstantiationCrossPackage)
+	}
+}
+
+func TestTypesAnalyzerClassifiesImportedConstraintKinds(t *testing.T) {
+	files := []model.SourceFile{
+		{Path: "go.mod", Content: "module example.com/tree\n\ngo 1.22\n"},
+		{Path: "constraints/constraints.go", Content: `package constraints
+
+type Number interface{ ~int | ~float64 }
+
+type Point struct{ X, Y int }
+`},
+		{Path: "a/a.go", Content: `package a
+
+import (
+	"example.com/tree/constraints"
+	"fmt"
+)
+
+func Print[T fmt.Stringer](t T)          {}
+func Sum[T constraints.Number](t T)      {}
+func Move[T constraints.Point](t T)      {}
+`},
+	}
+
+	result, err := NewTypesAnalyzer("").AnalyzeFiles(files)
+	if err != nil {
+		t.Fatalf("failed to analyze files: %v", err)
+	}
+
+	counters := result.Counters
+	if counters.ConstraintMethodInterface != 1 || counters.ConstraintTypeSetInterface != 1 || counters.ConstraintConcrete != 1 {
+		t.Errorf("got method=%d type set=%d concrete=%d, want 1 each",
+			counters.ConstraintMethodInterface, counters.ConstraintTypeSetInterface, counters.ConstraintConcrete)
+	}
+	if counters.ConstraintOther != 0 || counters.ConstraintOrdered != 0 {
+		t.Errorf("got other=%d ordered=%d, want 0", counters.ConstraintOther, counters.ConstraintOrdered)
+	}
+}
+
+func TestTypesAnalyzerCountsRangeOverFuncFields(t *testing.T) {
+	files := []model.SourceFile{
+		{Path: "go.mod", Content: "module example.com/tree\n\ngo 1.23\n"},
+		{Path: "a.go", Content: `package a
+
+type source struct {
+	each func(yield func(string) bool)
+	names []string
+}
+
+func use(s source) {
+	for name := range s.each {
+		_ = name
+	}
+	for _, name := range s.names {
+		_ = name
+	}
+}
+`},
+	}
+
+	result, err := NewTypesAnalyzer("").AnalyzeFiles(files)
+	if err != nil {
+		t.Fatalf("failed to analyze files: %v", err)
+	}
+	if result.Counters.RangeOverFunc != 1 {
+		t.Errorf("RangeOverFunc = %d, want 1", result.Counters.RangeOverFunc)
+	}
+}
+
+func TestTypesAnalyzerResolvesReplacedModules(t *testing.T) {
+	modCache := t.TempDir()
+	for dir, content := range map[string]string{
+		// Ohne Beachtung von replace würde die ursprüngliche Version mit einem Methoden-Interface gefunden
+		"example.com/upstream@v1.0.0": "package constraints\n\ntype Number interface{ String() string }\n",
+		"example.com/fork@v1.1.0":     "package constraints\n\ntype Number interface{ ~int | ~float64 }\n",
+		// Lokal ersetzte Module dürfen nicht aus dem Module-Cache gelesen werden
+		"example.com/local@v1.0.0": "package constraints\n\ntype Number interface{ ~int | ~float64 }\n",
+	} {
+		pkgDir := filepath.Join(modCache, filepath.FromSlash(dir), "constraints")
+		if err := os.MkdirAll(pkgDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(pkgDir, "constraints.go"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files := []model.SourceFile{
+		{Path: "go.mod", Content: `module example.com/tree
+
+go 1.22
+
+require (
+	example.com/upstream v1.0.0
+	example.com/local v1.0.0
+)
+
+replace example.com/upstream => example.com/fork v1.1.0
+
+replace example.com/local => ../local
+`},
+		{Path: "a/a.go", Content: `package a
+
+import (
+	"example.com/local/constraints"
+	upstream "example.com/upstream/constraints"
+)
+
+func Sum[T upstream.Number](t T)     {}
+func Scale[T constraints.Number](t T) {}
+`},
+	}
+
+	result, err := NewTypesAnalyzer(modCache).AnalyzeFiles(files)
+	if err != nil {
+		t.Fatalf("failed to analyze files: %v", err)
+	}
+
+	// Das lokal ersetzte Modul liegt nicht im Baum und wird daher nicht aufgelöst
+	counters := result.Counters
+	if counters.ConstraintTypeSetInterface != 1 || counters.ConstraintMethodInterface != 0 || counters.ConstraintOther != 1 {
+		t.Errorf("got type set=%d method=%d other=%d, want 1, 0 and 1",
+			counters.ConstraintTypeSetInterface, counters.ConstraintMethodInterface, counters.ConstraintOther)
+	}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"GoParser/utils"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// dependencyCache holds type-checked packages from GOROOT and the module cache.
+// These packages never change between repositories, so they are shared by all analyses.
+type dependencyCache struct {
+	fset     *token.FileSet
+	ctxt     build.Context
+	modCache string
+	packages map[string]*types.Package // keyed by directory on disk
+}
+
+func newDependencyCache(modCache string) *dependencyCache {
+	ctxt := build.Default
+	// Pure Go variants of std packages are sufficient for type checking and avoid cgo
+	ctxt.CgoEnabled = false
+
+	return &dependencyCache{
+		fset:     token.NewFileSet(),
+		ctxt:     ctxt,
+		modCache: modCache,
+		packages: make(map[string]*types.Package),
+	}
+}
+
+// sourceTreeImporter implements types.ImporterFrom for one analyzed source tree.
+// Imports are resolved without network access in this order:
+// packages of the tree itself, the standard library in GOROOT and the local module cache.
+// Files of the tree are only read when their package is imported.
+type sourceTreeImporter struct {
+	fset    *token.FileSet
+	deps    *dependencyCache
+	tree    utils.SourceTree
+	modules map[string]string         // module path -> module directory within the tree
+	require map[string]module.Version // module path -> location of the required version in the module cache
+	dirs    map[string][]string
+	loaded  map[string]*types.Package
+	loading map[string]bool
+}
+
+func newSourceTreeImporter(fset *token.FileSet, deps *dependencyCache, tree utils.SourceTree) *sourceTreeImporter {
+	imp := &sourceTreeImporter{
+		fset:    fset,
+		deps:    deps,
+		tree:    tree,
+		modules: make(map[string]string),
+		require: make(map[string]module.Version),
+		dirs:    make(map[string][]string),
+		loaded:  make(map[string]*types.Package),
+		loading: make(map[string]bool),
+	}
+
+	for _, filePath := range tree.Paths() {
+		if path.Base(filePath) == "go.mod" {
+			content, err := tree.ReadFile(filePath)
+			if err != nil {
+				continue
+			}
+			modFile, err := utils.ParseGoMod(content)
+			if err != nil {
+				continue
+			}
+			if modFile.Module != "" {
+				imp.modules[modFile.Module] = path.Dir(filePath)
+			}
+			for modPath, version := range modFile.Require {
+				replacement, replaced := modFile.Replace[modPath]
+				switch {
+				case !replaced:
+					imp.require[modPath] = module.Version{Path: modPath, Version: version}
+				case replacement.Version != "":
+					imp.require[modPath] = replacement
+				}
+				// Durch ein lokales Verzeichnis ersetzte Module liegen im Baum (siehe modules) oder sind nicht verfügbar
+			}
+			continue
+		}
+		if strings.HasSuffix(filePath, ".go") {
+			imp.dirs[path.Dir(filePath)] = append(imp.dirs[path.Dir(filePath)], filePath)
+		}
+	}
+
+	return imp
+}
+
+func (imp *sourceTreeImporter) Import(importPath string) (*types.Package, error) {
+	return imp.ImportFrom(importPath, "", 0)
+}
+
+func (imp *sourceTreeImporter) ImportFrom(importPath, dir string, _ types.ImportMode) (*types.Package, error) {
+	if importPath == "unsafe" {
+		return types.Unsafe, nil
+	}
+	if pkg, ok := imp.loaded[importPath]; ok {
+		return pkg, nil
+	}
+	if imp.loading[importPath] {
+		return nil, fmt.Errorf("import cycle via %s", importPath)
+	}
+	imp.loading[importPath] = true
+	defer delete(imp.loading, importPath)
+
+	var pkg *types.Package
+	var err error
+	if treeDir, ok := imp.treeDir(importPath); ok {
+		pkg, err = imp.checkTreePackage(importPath, treeDir)
+	} else if isStdImportPath(importPath) {
+		pkg, err = imp.deps.load(importPath, filepath.Join(imp.deps.ctxt.GOROOT, "src", importPath), imp)
+	} else if strings.HasPrefix(dir, imp.deps.ctxt.GOROOT) {
+		// Packages vendored into the standard library (golang.org/x/...)
+		pkg, err = imp.deps.load(importPath, filepath.Join(imp.deps.ctxt.GOROOT, "src", "vendor", importPath), imp)
+	} else if modDir, ok := imp.modCacheDir(importPath); ok {
+		pkg, err = imp.deps.load(importPath, modDir, imp)
+	} else {
+		err = fmt.Errorf("package %s not found in source tree, GOROOT or module cache", importPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	imp.loaded[importPath] = pkg
+	return pkg, nil
+}
+
+// treeDir maps an import path to a directory of the analyzed tree using the longest matching module path
+func (imp *sourceTreeImporter) treeDir(importPath string) (string, bool) {
+	modPath, ok := longestModulePrefix(importPath, imp.modules)
+	if !ok {
+		return "", false
+	}
+	dir := path.Join(imp.modules[modPath], strings.TrimPrefix(importPath, modPath))
+	_, exists := imp.dirs[dir]
+	return dir, exists
+}
+
+// importPathFor returns the import path of a directory of the analyzed tree.
+// Directories outside of any module keep their directory as import path.
+func (imp *sourceTreeImporter) importPathFor(dir string) string {
+	best := ""
+	for modPath, modDir := range imp.modules {
+		if (dir == modDir || modDir == "." || strings.HasPrefix(dir, modDir+"/")) && (best == "" || len(modDir) > len(imp.modules[best])) {
+			best = modPath
+		}
+	}
+	if best == "" {
+		return dir
+	}
+	modDir := imp.modules[best]
+	if dir == modDir {
+		return best
+	}
+	if modDir == "." {
+		return best + "/" + dir
+	}
+	return best + strings.TrimPrefix(dir, modDir)
+}
+
+// modCacheDir maps an import path to its directory in the module cache using the required module versions.
+// Modules replaced by another module version are looked up at the replacement.
+func (imp *sourceTreeImporter) modCacheDir(importPath string) (string, bool) {
+	if imp.deps.modCache == "" {
+		return "", false
+	}
+	modPath, ok := longestModulePrefix(importPath, imp.require)
+	if !ok {
+		return "", false
+	}
+	location := imp.require[modPath]
+	escaped, err := utils.EscapeModulePath(location.Path)
+	if err != nil {
+		return "", false
+	}
+	dir := filepath.Join(imp.deps.modCache, filepath.FromSlash(escaped)+"@"+location.Version, filepath.FromSlash(strings.TrimPrefix(importPath, modPath)))
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", false
+	}
+	return dir, true
+}
+
+// checkTreePackage type-checks the non-test files of a directory of the analyzed tree
+func (imp *sourceTreeImporter) checkTreePackage(importPath, dir string) (*types.Package, error) {
+	ctxt := imp.deps.ctxt
+	ctxt.JoinPath = path.Join
+	ctxt.OpenFile = func(name string) (io.ReadCloser, error) {
+		content, err := imp.tree.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(strings.NewReader(content)), nil
+	}
+
+	var files []*ast.File
+	for _, filePath := range imp.dirs[dir] {
+		if strings.HasSuffix(filePath, "_test.go") {
+			continue
+		}
+		if match, err := ctxt.MatchFile(dir, path.Base(filePath)); err != nil || !match {
+			continue
+		}
+		content, err := imp.tree.ReadFile(filePath)
+		if err != nil {
+			continue
+		}
+		file, err := parser.ParseFile(imp.fset, filePath, content, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no buildable Go files for %s in %s", importPath, dir)
+	}
+
+	return checkDependency(importPath, imp.fset, files, imp), nil
+}
+
+// load type-checks a package directory on disk and caches the result for later analyses
+func (deps *dependencyCache) load(importPath, dir string, importer types.ImporterFrom) (*types.Package, error) {
+	if pkg, ok := deps.packages[dir]; ok {
+		return pkg, nil
+	}
+
+	buildPkg, err := deps.ctxt.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*ast.File
+	for _, name := range buildPkg.GoFiles {
+		file, err := parser.ParseFile(deps.fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		files = append(files, file)
+	}
+
+	pkg := checkDependency(importPath, deps.fset, files, importer)
+	deps.packages[dir] = pkg
+	return pkg, nil
+}
+
+// checkDependency type-checks an imported package. Only its exported API is needed,
+// so function bodies are skipped and errors are tolerated.
+func checkDependency(importPath string, fset *token.FileSet, files []*ast.File, importer types.ImporterFrom) *types.Package {
+	conf := types.Config{
+		Importer:         importer,
+		IgnoreFuncBodies: true,
+		FakeImportC:      true,
+		Error:            func(error) {},
+	}
+	pkg, _ := conf.Check(importPath, fset, files, nil)
+	return pkg
+}
+
+func isStdImportPath(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+func longestModulePrefix[V any](importPath string, modules map[string]V) (string, bool) {
+	candidates := make([]string, 0, len(modules))
+	for modPath := range modules {
+		if importPath == modPath || strings.HasPrefix(importPath, modPath+"/") {
+			candidates = append(candidates, modPath)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+	sort.Slice(candidates, func(i, j int) bool { return len(candidates[i]) > len(candidates[j]) })
+	return candidates[0], true
+}
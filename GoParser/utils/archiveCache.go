@@ -0,0 +1,198 @@
+package utils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ArchiveCache speichert heruntergeladene Zipballs auf der Festplatte,
+// damit ein erneuter Lauf (z.B. nach einer Änderung am Analyzer) nichts erneut herunterladen muss.
+// Ein Archiv ist über owner/repo/commit-SHA eindeutig bestimmt und ändert sich daher nie.
+// Gespeichert werden nur die Dateien, die die Analyse liest (.go, go.mod, go.work), nicht das ganze Archiv.
+//
+// Layout: <dir>/<owner>/<repo>/<sha>.zip sowie <dir>/<owner>/<repo>/latest mit dem zuletzt geladenen SHA
+// des Standardbranches. Für gepinnte Refs liegt der aufgelöste SHA unter <dir>/<owner>/<repo>/refs/<ref>.
+// Repositories anderer Hosts liegen unter <dir>/<host>/<owner>/<repo>, Archive von URLs unter
+// <dir>/archive/<Hash der URL>. tar.gz-Archive werden dabei in ZIP umgewandelt.
+type ArchiveCache struct {
+	dir string
+}
+
+func NewArchiveCache(dir string) *ArchiveCache {
+	return &ArchiveCache{dir: dir}
+}
+
+// DefaultArchiveCacheDir liefert das Standardverzeichnis des Caches im Cache-Verzeichnis des Benutzers
+func DefaultArchiveCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "GoParser", "archives"), nil
+}
+
+func (c *ArchiveCache) repoDir(owner, repo string) string {
+	return filepath.Join(c.dir, owner, repo)
+}
+
+// Path liefert den Pfad des Archivs eines Commits, falls es im Cache liegt
+func (c *ArchiveCache) Path(owner, repo, sha string) (string, bool) {
+	name := filepath.Join(c.repoDir(owner, repo), sha+".zip")
+	if _, err := os.Stat(name); err != nil {
+		return "", false
+	}
+	return name, true
+}
+
+// Put legt die Quelldateien des heruntergeladenen Archivs tmp im Cache ab und merkt sich, zu welchem Commit
+// ref aufgelöst wurde (leere Ref = Standardbranch). Liefert den Pfad im Cache; tmp wird danach gelöscht.
+// Das Archiv wird erst unter einem temporären Namen geschrieben, damit ein Abbruch keine halben Archive hinterlässt.
+// Schlägt das Ablegen fehl, bleibt tmp erhalten.
+func (c *ArchiveCache) Put(owner, repo, ref, sha, tmp string) (string, error) {
+	dir := c.repoDir(owner, repo)
+	if err := os.MkdirAll(filepath.Join(dir, "refs"), 0o755); err != nil {
+		return "", err
+	}
+
+	name := filepath.Join(dir, sha+".zip")
+	if err := writeAtomic(name, func(w io.Writer) error { return compactArchive(tmp, w) }); err != nil {
+		return "", err
+	}
+	os.Remove(tmp)
+	return name, writeFileAtomic(c.refFile(owner, repo, ref), []byte(sha+"\n"))
+}
+
+// Resolve liefert den SHA, zu dem ref beim letzten Download aufgelöst wurde (für den Offline-Modus)
+func (c *ArchiveCache) Resolve(owner, repo, ref string) (string, bool) {
+	data, err := os.ReadFile(c.refFile(owner, repo, ref))
+	if err != nil {
+		return "", false
+	}
+	sha := strings.TrimSpace(string(data))
+	if _, err := os.Stat(filepath.Join(c.repoDir(owner, repo), sha+".zip")); err != nil {
+		return "", false
+	}
+	return sha, true
+}
+
+// refFile liefert den Pfad der Datei, die den SHA einer Ref enthält. Refs wie "release/v1" werden escaped.
+func (c *ArchiveCache) refFile(owner, repo, ref string) string {
+	if ref == "" {
+		return filepath.Join(c.repoDir(owner, repo), "latest")
+	}
+	return filepath.Join(c.repoDir(owner, repo), "refs", url.PathEscape(ref))
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	return writeAtomic(path, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// writeAtomic schreibt eine Datei über write in eine temporäre Datei im selben Verzeichnis und benennt sie
+// erst danach um
+func writeAtomic(path string, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("konnte Archiv nicht im Cache ablegen: %w", err)
+	}
+	return nil
+}
+
+// compactArchive schreibt die Quelldateien (siehe isSourceFile) des ZIP- oder tar.gz-Archivs src als ZIP nach w.
+// Zipballs enthalten sonst auch Testdaten, Binärdateien und Dokumentation, die im Cache nur Platz belegen.
+// ZIP-Einträge werden unverändert komprimiert übernommen, die Pfade aller Einträge bleiben erhalten.
+func compactArchive(src string, w io.Writer) error {
+	isGzip, err := hasGzipMagic(src)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	if isGzip {
+		err = copyTarGzSources(src, zw)
+	} else {
+		err = copyZipSources(src, zw)
+	}
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func copyZipSources(src string, zw *zip.Writer) error {
+	reader, err := zip.OpenReader(src)
+	if err != nil {
+		return fmt.Errorf("konnte ZIP nicht entpacken: %w", err)
+	}
+	defer reader.Close()
+
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() || !isSourceFile(f.Name) {
+			continue
+		}
+		if err := zw.Copy(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyTarGzSources(src string, zw *zip.Writer) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(bufio.NewReader(f))
+	if err != nil {
+		return fmt.Errorf("konnte tar.gz nicht entpacken: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("konnte tar.gz nicht entpacken: %w", err)
+		}
+		entry := strings.TrimPrefix(header.Name, "./")
+		if header.Typeflag != tar.TypeReg || !isSourceFile(entry) || !filepath.IsLocal(filepath.FromSlash(entry)) {
+			continue
+		}
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: entry, Method: zip.Deflate, Modified: header.ModTime.In(time.UTC)})
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(fw, tr); err != nil {
+			return err
+		}
+	}
+}
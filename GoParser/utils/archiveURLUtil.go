@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ArchiveURLProvider lädt ein ZIP- oder tar.gz-Archiv von einer beliebigen URL, z.B. ein Release-Archiv.
+// Da es keinen Commit gibt, wird der SHA-256 des Archivs als Commit gespeichert; ein geändertes Archiv
+// unter derselben URL ist dadurch in der Datenbank erkennbar.
+type ArchiveURLProvider struct {
+	httpSource
+
+	// Cache ist optional; offline wird das zuletzt geladene Archiv der URL analysiert
+	Cache *ArchiveCache
+	// Offline analysiert ausschließlich Archive aus dem Cache
+	Offline bool
+}
+
+func NewArchiveURLProvider() *ArchiveURLProvider {
+	return &ArchiveURLProvider{httpSource: newHTTPSource("Archive")}
+}
+
+// FetchRepository lädt das Archiv unter entry.URL. Eine Ref ist nicht möglich, da die URL den Inhalt bestimmt.
+func (p *ArchiveURLProvider) FetchRepository(entry RepositoryEntry) (SourceTree, string, error) {
+	if entry.Ref != "" {
+		return nil, "", fmt.Errorf("archive %s cannot be pinned to ref %q", entry.URL, entry.Ref)
+	}
+
+	// Das Archiv muss ohnehin geladen werden, um seinen Hash zu bestimmen
+	var tmp string
+	resolve := func() (string, error) {
+		var err error
+		tmp, err = p.getFile(context.Background(), entry.URL)
+		if err != nil {
+			return "", fmt.Errorf("konnte Archiv nicht laden: %w", err)
+		}
+		return fileSHA256(tmp)
+	}
+	// Ab der Übergabe gehört die temporäre Datei fetchArchive (Cache oder temporärer Baum)
+	handedOver := false
+	download := func(string) (string, error) {
+		handedOver = true
+		return tmp, nil
+	}
+
+	// Im Cache liegen die Archive unter dem Hash der URL, da diese beliebige Zeichen enthalten kann
+	urlSum := sha256.Sum256([]byte(entry.URL))
+	tree, sha, err := fetchArchive(p.Cache, p.Offline, "archive", hex.EncodeToString(urlSum[:8]), "", resolve, download)
+	if tmp != "" && !handedOver {
+		// Das Archiv lag bereits im Cache oder sein Hash konnte nicht bestimmt werden
+		os.Remove(tmp)
+	}
+	return tree, sha, err
+}
+
+// fileSHA256 liefert den SHA-256 einer Datei, ohne sie vollständig in den Speicher zu lesen
+func fileSHA256(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -2,12 +2,63 @@ package utils
 
 import (
 	"encoding/csv"
+	"fmt"
+	"log"
 	"os"
+	"slices"
 	"strings"
 )
 
-// GetOwnerAndRepo liest eine CSV-Datei ein und gibt für jede Zeile owner und repo zurück
-func GetOwnerAndRepo(filename string) ([][2]string, error) {
+// Provider, über die Repositories geladen werden können
+const (
+	ProviderGitHub  = "github"
+	ProviderGitLab  = "gitlab"
+	ProviderGitea   = "gitea" // auch Forgejo, z.B. codeberg.org
+	ProviderArchive = "archive"
+	ProviderModule  = "module" // Go-Modul über GOPROXY
+)
+
+// knownHosts ordnet öffentlichen Hosts ihren Provider zu. Selbst gehostete Instanzen werden über die
+// Spalte "Provider" der Input-Datei ausgewählt.
+var knownHosts = map[string]string{
+	"github.com":   ProviderGitHub,
+	"gitlab.com":   ProviderGitLab,
+	"codeberg.org": ProviderGitea,
+	"gitea.com":    ProviderGitea,
+}
+
+// RepositoryEntry ist ein Repository aus der Input-Datei.
+// Ref ist optional und pinnt die Analyse auf einen Branch, Tag, Commit-SHA oder
+// den letzten Commit vor einem Datum (YYYY-MM-DD oder RFC 3339). Ohne Ref wird der Standardbranch analysiert.
+type RepositoryEntry struct {
+	Provider string
+	Host     string // z.B. github.com oder gitlab.example.com; leer beim Archiv-Provider
+	Owner    string // bei GitLab ggf. mit Untergruppen, z.B. "group/subgroup"
+	Repo     string
+	URL      string // nur beim Archiv-Provider: Adresse eines .zip- oder .tar.gz-Archivs
+	Module   string // nur beim Modul-Provider: Modulpfad; die Version steht in Ref
+	Ref      string
+}
+
+// Name liefert den Repository-Namen: "owner/repo" für GitHub (wie in bestehenden Datenbanken),
+// "host/owner/repo" für andere Hosts, die URL für Archive und den Modulpfad für Module
+func (e RepositoryEntry) Name() string {
+	switch {
+	case e.Provider == ProviderArchive:
+		return e.URL
+	case e.Provider == ProviderModule:
+		return e.Module
+	case e.Host == "" || e.Host == "github.com":
+		return e.Owner + "/" + e.Repo
+	}
+	return e.Host + "/" + e.Owner + "/" + e.Repo
+}
+
+// GetOwnerAndRepo liest eine CSV-Datei ein und gibt für jede Zeile das Repository und ggf. die Ref zurück.
+// Die Ref steht in einer optionalen Spalte mit der Überschrift "Ref", der Provider in einer optionalen
+// Spalte "Provider" (github, gitlab, gitea, forgejo, archive oder module). Ohne Provider wird er aus dem Host
+// abgeleitet. Zeilen, die nicht zugeordnet werden können, werden mit Begründung geloggt und übersprungen.
+func GetOwnerAndRepo(filename string) ([]RepositoryEntry, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, err
@@ -15,31 +66,110 @@ func GetOwnerAndRepo(filename string) ([][2]string, error) {
 	defer file.Close()
 
 	reader := csv.NewReader(file)
+	// Zeilen mit und ohne Ref-Spalte dürfen gemischt vorkommen
+	reader.FieldsPerRecord = -1
 	records, err := reader.ReadAll()
 	if err != nil {
 		return nil, err
 	}
 
-	var result [][2]string
+	refColumn, providerColumn := -1, -1
+	var result []RepositoryEntry
 	for i, record := range records {
-		// Überspringe die Kopfzeile
+		// Kopfzeile: nur die Position der Ref- und Provider-Spalte merken
 		if i == 0 {
+			for j, column := range record {
+				switch strings.ToLower(strings.TrimSpace(column)) {
+				case "ref":
+					refColumn = j
+				case "provider":
+					providerColumn = j
+				}
+			}
 			continue
 		}
 		if len(record) < 2 {
+			log.Printf("Skipping line %d of %s: no repository column", i+1, filename)
 			continue
 		}
 
-		// Repository im Format "github.com/owner/repo"
-		parts := strings.Split(record[1], "/")
-		if len(parts) < 3 {
+		provider := ""
+		if providerColumn >= 0 && providerColumn < len(record) {
+			provider = strings.TrimSpace(record[providerColumn])
+		}
+		entry, err := ParseRepository(record[1], provider)
+		if err != nil {
+			log.Printf("Skipping line %d of %s: %v", i+1, filename, err)
 			continue
 		}
-		owner := parts[1]
-		repo := parts[2]
+		if refColumn >= 0 && refColumn < len(record) {
+			// Eine leere Ref-Spalte überschreibt nicht die Version aus "modul@version"
+			if ref := strings.TrimSpace(record[refColumn]); ref != "" {
+				entry.Ref = ref
+			}
+		}
 
-		result = append(result, [2]string{owner, repo})
+		result = append(result, entry)
 	}
 
 	return result, nil
 }
+
+// ParseRepository wandelt ein Repository wie "github.com/owner/repo", "gitlab.com/group/sub/project",
+// die URL eines Archivs oder ein Modul wie "golang.org/x/exp@v0.0.0-20240506185415-9bf2ced13842" in einen
+// RepositoryEntry um. Ein Schema und die Endung ".git" sind erlaubt. provider ist optional und wird für
+// selbst gehostete Instanzen und Module ohne Version benötigt.
+func ParseRepository(repository, provider string) (RepositoryEntry, error) {
+	repository = strings.TrimSpace(repository)
+	provider = strings.ToLower(provider)
+	if provider == "forgejo" {
+		provider = ProviderGitea
+	}
+
+	if provider == ProviderArchive || (provider == "" && isArchiveURL(repository)) {
+		if !strings.HasPrefix(repository, "https://") && !strings.HasPrefix(repository, "http://") {
+			return RepositoryEntry{}, fmt.Errorf("archive %q is not an http(s) URL", repository)
+		}
+		return RepositoryEntry{Provider: ProviderArchive, URL: repository}, nil
+	}
+
+	if modulePath, version, found := strings.Cut(repository, "@"); provider == ProviderModule || (provider == "" && found && !strings.Contains(repository, "://")) {
+		if modulePath == "" || (found && version == "") {
+			return RepositoryEntry{}, fmt.Errorf("module %q is not of the form path[@version]", repository)
+		}
+		return RepositoryEntry{Provider: ProviderModule, Module: modulePath, Ref: version}, nil
+	}
+
+	trimmed := repository
+	if _, rest, found := strings.Cut(trimmed, "://"); found {
+		trimmed = rest
+	}
+	trimmed = strings.TrimSuffix(strings.TrimSuffix(trimmed, "/"), ".git")
+	parts := strings.Split(trimmed, "/")
+	if len(parts) < 3 || slices.Contains(parts, "") {
+		return RepositoryEntry{}, fmt.Errorf("repository %q is not of the form host/owner/repo", repository)
+	}
+
+	host := strings.ToLower(parts[0])
+	if provider == "" {
+		provider = knownHosts[host]
+	}
+	switch provider {
+	case "":
+		return RepositoryEntry{}, fmt.Errorf("unknown host %s of %q - set the Provider column for self-hosted instances and modules", host, repository)
+	case ProviderGitLab:
+		// GitLab erlaubt verschachtelte Gruppen
+		return RepositoryEntry{Provider: provider, Host: host, Owner: strings.Join(parts[1:len(parts)-1], "/"), Repo: parts[len(parts)-1]}, nil
+	case ProviderGitHub, ProviderGitea:
+		if len(parts) != 3 {
+			return RepositoryEntry{}, fmt.Errorf("repository %q is not of the form host/owner/repo", repository)
+		}
+		return RepositoryEntry{Provider: provider, Host: host, Owner: parts[1], Repo: parts[2]}, nil
+	}
+	return RepositoryEntry{}, fmt.Errorf("unknown provider %q for %q", provider, repository)
+}
+
+func isArchiveURL(repository string) bool {
+	lower := strings.ToLower(repository)
+	return strings.HasSuffix(lower, ".zip") || strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"GoParser/model"
+	"regexp"
+	"strings"
+)
+
+// generatedHeader ist die Kopfzeile generierter Dateien nach https://go.dev/s/generatedcode
+var generatedHeader = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// FileCategory ordnet eine Datei anhand ihres Pfads (relativ zum Projekt, mit '/') und ihres Inhalts
+// einer Kategorie zu. Fremder Code hat Vorrang vor generiertem Code, dieser vor Tests und Beispielen.
+func FileCategory(path, content string) string {
+	dirs := strings.Split(path, "/")
+	dirs = dirs[:len(dirs)-1]
+
+	switch {
+	case containsAny(dirs, "vendor", "third_party"):
+		return model.FileCategoryVendored
+	case isGenerated(content):
+		return model.FileCategoryGenerated
+	case strings.HasSuffix(path, "_test.go") || containsAny(dirs, "testdata"):
+		return model.FileCategoryTest
+	case containsAny(dirs, "example", "examples", "_example", "_examples"):
+		return model.FileCategoryExample
+	}
+	return model.FileCategoryProduction
+}
+
+// isGenerated sucht die Kopfzeile generierter Dateien vor der package-Klausel
+func isGenerated(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if strings.HasPrefix(line, "package ") {
+			return false
+		}
+		if generatedHeader.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(dirs []string, names ...string) bool {
+	for _, dir := range dirs {
+		for _, name := range names {
+			if dir == name {
+				return true
+			}
+		}
+	}
+	return false
+}
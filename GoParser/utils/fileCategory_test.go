@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"GoParser/model"
+	"testing"
+)
+
+func TestFileCategory(t *testing.T) {
+	generated := "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage pb\n"
+	tests := []struct {
+		path, content, want string
+	}{
+		{"main.go", "package main\n", model.FileCategoryProduction},
+		{"pkg/list_test.go", "package pkg\n", model.FileCategoryTest},
+		{"pkg/testdata/input.go", "package input\n", model.FileCategoryTest},
+		{"api/api.pb.go", generated, model.FileCategoryGenerated},
+		{"api/api_test.go", generated, model.FileCategoryGenerated},
+		{"vendor/github.com/x/y/y.go", generated, model.FileCategoryVendored},
+		{"third_party/forked/list.go", "package list\n", model.FileCategoryVendored},
+		{"examples/basic/main.go", "package main\n", model.FileCategoryExample},
+		{"example_test.go", "package pkg\n", model.FileCategoryTest},
+		// Die Kopfzeile zählt nur vor der package-Klausel
+		{"late.go", "package late\n\n// Code generated by hand. DO NOT EDIT.\n", model.FileCategoryProduction},
+	}
+
+	for _, tt := range tests {
+		if got := FileCategory(tt.path, tt.content); got != tt.want {
+			t.Errorf("FileCategory(%q) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
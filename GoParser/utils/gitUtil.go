@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"GoParser/model"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// HistorySamplingMonthly wählt den letzten Commit jedes Monats
+	HistorySamplingMonthly = "monthly"
+	// HistorySamplingTags wählt den Commit jedes Tags
+	HistorySamplingTags = "tags"
+)
+
+// SelectHistoryCommits bestimmt die Stände eines lokalen Git-Repositories, die analysiert werden sollen.
+// sampling ist "monthly", "tags" oder eine Zahl N (jeder N-te Commit). Betrachtet wird die First-Parent-Historie
+// von HEAD, also die Stände, die tatsächlich auf dem Hauptbranch lagen. Der aktuelle Stand ist außer bei "tags" immer enthalten.
+// Es werden ausschließlich lokale Daten aus .git verwendet.
+func SelectHistoryCommits(repoPath, sampling string) ([]model.Commit, error) {
+	if sampling == HistorySamplingTags {
+		return listGitTags(repoPath)
+	}
+
+	every := 0
+	if sampling != HistorySamplingMonthly {
+		n, err := strconv.Atoi(sampling)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("unknown history sampling %q - use %q, %q or a number of commits", sampling, HistorySamplingMonthly, HistorySamplingTags)
+		}
+		every = n
+	}
+
+	commits, err := listGitCommits(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if every > 0 {
+		return sampleEveryN(commits, every), nil
+	}
+	return sampleMonthly(commits), nil
+}
+
+// FetchGitTree liefert alle .go-Dateien sowie go.mod- und go.work-Dateien eines Commits direkt aus .git,
+// ohne das Arbeitsverzeichnis zu verändern. git archive schreibt dazu wie bei GitHub ein ZIP-Archiv in eine
+// temporäre Datei, die beim Schließen des Baums gelöscht wird; liegt repoPath in einem Unterverzeichnis
+// des Repositories, enthält das Archiv nur dieses Verzeichnis.
+func FetchGitTree(repoPath, sha string) (SourceTree, error) {
+	f, err := os.CreateTemp("", "goparser-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command("git", "-C", repoPath, "archive", "--format=zip", "--prefix=root/", sha)
+	var stderr bytes.Buffer
+	cmd.Stdout = f
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("git archive: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return openArchive(f.Name(), "root/", true)
+}
+
+// listGitCommits liefert die First-Parent-Historie von HEAD, vom ältesten zum neuesten Commit
+func listGitCommits(repoPath string) ([]model.Commit, error) {
+	// Liegt das Projekt in einem Unterverzeichnis, zählen nur Commits, die es verändern
+	out, err := runGit(repoPath, "log", "--first-parent", "--reverse", "--format=%H %cI", "HEAD", "--", ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []model.Commit
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		commit, err := parseCommitLine(line)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// listGitTags liefert den Commit jedes Tags, sortiert nach Commit-Datum
+func listGitTags(repoPath string) ([]model.Commit, error) {
+	out, err := runGit(repoPath, "tag", "--list")
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []model.Commit
+	for _, tag := range strings.Fields(string(out)) {
+		// ^{commit} löst auch annotierte Tags auf ihren Commit auf
+		line, err := runGit(repoPath, "show", "-s", "--format=%H %cI", tag+"^{commit}")
+		if err != nil {
+			return nil, err
+		}
+		commit, err := parseCommitLine(strings.TrimSpace(string(line)))
+		if err != nil {
+			return nil, err
+		}
+		commit.Tag = tag
+		commits = append(commits, commit)
+	}
+
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].Date.Before(commits[j].Date)
+	})
+	return commits, nil
+}
+
+func parseCommitLine(line string) (model.Commit, error) {
+	sha, date, found := strings.Cut(line, " ")
+	if !found {
+		return model.Commit{}, fmt.Errorf("unexpected git output %q", line)
+	}
+	commitDate, err := time.Parse(time.RFC3339, date)
+	if err != nil {
+		return model.Commit{}, err
+	}
+	return model.Commit{SHA: sha, Date: commitDate}, nil
+}
+
+// sampleMonthly wählt pro Monat den letzten Commit, also den Stand am Monatsende
+func sampleMonthly(commits []model.Commit) []model.Commit {
+	lastOfMonth := make(map[string]model.Commit)
+	var months []string
+	for _, commit := range commits {
+		month := commit.Date.UTC().Format("2006-01")
+		if _, ok := lastOfMonth[month]; !ok {
+			months = append(months, month)
+		}
+		lastOfMonth[month] = commit
+	}
+
+	// Commit-Daten sind in der Historie nicht zwingend monoton, daher nach Monat sortieren
+	sort.Strings(months)
+	var sampled []model.Commit
+	for _, month := range months {
+		sampled = append(sampled, lastOfMonth[month])
+	}
+	return sampled
+}
+
+// sampleEveryN wählt jeden n-ten Commit ab dem ältesten und zusätzlich den neuesten
+func sampleEveryN(commits []model.Commit, n int) []model.Commit {
+	var sampled []model.Commit
+	for i := 0; i < len(commits); i += n {
+		sampled = append(sampled, commits[i])
+	}
+	if len(commits) > 0 && (len(commits)-1)%n != 0 {
+		sampled = append(sampled, commits[len(commits)-1])
+	}
+	return sampled
+}
+
+func runGit(repoPath string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", repoPath}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// newTestGitRepository erstellt ein Git-Repository mit einem Commit pro Eintrag in dates.
+// Jeder Commit fügt eine Datei hinzu; der zweite Commit wird mit v1.0 getaggt.
+func newTestGitRepository(t *testing.T, dates []string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(date string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date,
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	git("", "init", "-q", "-b", "main")
+	for i, date := range dates {
+		name := filepath.Join(dir, "pkg", string(rune('a'+i))+".go")
+		os.MkdirAll(filepath.Dir(name), 0o755)
+		if err := os.WriteFile(name, []byte("package pkg\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		git(date, "add", "-A")
+		git(date, "commit", "-q", "-m", "commit")
+		if i == 1 {
+			git(date, "tag", "v1.0")
+		}
+	}
+	return dir
+}
+
+func TestSelectHistoryCommits(t *testing.T) {
+	dir := newTestGitRepository(t, []string{
+		"2022-03-01T10:00:00Z",
+		"2022-03-20T10:00:00Z",
+		"2022-05-02T10:00:00Z",
+		"2022-05-03T10:00:00Z",
+		"2022-05-04T10:00:00Z",
+	})
+
+	monthly, err := SelectHistoryCommits(dir, HistorySamplingMonthly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(monthly) != 2 || monthly[0].Date.Day() != 20 || monthly[1].Date.Day() != 4 {
+		t.Errorf("monthly sampling returned %v, want the last commit of March and May", monthly)
+	}
+
+	everyTwo, err := SelectHistoryCommits(dir, "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(everyTwo) != 3 {
+		t.Errorf("sampling every 2 commits returned %d commits, want 3", len(everyTwo))
+	}
+
+	everyThree, err := SelectHistoryCommits(dir, "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Commit 1 und 4 sowie der neueste Commit
+	if len(everyThree) != 3 || everyThree[2].Date.Day() != 4 {
+		t.Errorf("sampling every 3 commits returned %v, want 3 commits ending with the newest", everyThree)
+	}
+
+	tags, err := SelectHistoryCommits(dir, HistorySamplingTags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0].Tag != "v1.0" || tags[0].SHA != monthly[0].SHA {
+		t.Errorf("tag sampling returned %v, want v1.0 at the second commit", tags)
+	}
+
+	if _, err := SelectHistoryCommits(dir, "weekly"); err == nil {
+		t.Errorf("expected error for unknown sampling")
+	}
+}
+
+func TestFetchGitTree(t *testing.T) {
+	dir := newTestGitRepository(t, []string{"2022-03-01T10:00:00Z", "2022-04-01T10:00:00Z"})
+
+	commits, err := SelectHistoryCommits(dir, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := FetchGitTree(dir, commits[0].SHA)
+	files, _, err := readTree(tree, commits[0].SHA, err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Path != "pkg/a.go" {
+		t.Errorf("first commit contains %v, want only pkg/a.go", files)
+	}
+
+	// Ein Unterverzeichnis wird wie ein eigenes Projekt behandelt
+	tree, err = FetchGitTree(filepath.Join(dir, "pkg"), commits[1].SHA)
+	files, _, err = readTree(tree, commits[1].SHA, err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0].Path != "a.go" {
+		t.Errorf("second commit of pkg contains %v, want a.go and b.go", files)
+	}
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// GiteaProvider lädt Repositories über die REST-API (v1) von Gitea und Forgejo, z.B. von codeberg.org
+// oder einer selbst gehosteten Instanz. Der Host kommt aus dem RepositoryEntry.
+type GiteaProvider struct {
+	httpSource
+
+	// Scheme der API-Adresse, standardmäßig "https"
+	Scheme string
+	// Cache ist optional; ist er gesetzt, werden Archive zuerst dort gesucht und nach dem Download abgelegt
+	Cache *ArchiveCache
+	// Offline analysiert ausschließlich Archive aus dem Cache
+	Offline bool
+}
+
+// NewGiteaProvider erzeugt einen Provider; token ist optional und wird für private Repositories benötigt
+func NewGiteaProvider(token string) *GiteaProvider {
+	p := &GiteaProvider{httpSource: newHTTPSource("Gitea"), Scheme: "https"}
+	if token != "" {
+		p.header.Set("Authorization", "token "+token)
+	}
+	return p
+}
+
+// FetchRepository lädt das Archiv des Commits, zu dem entry.Ref aufgelöst wird (siehe resolveCommit)
+func (p *GiteaProvider) FetchRepository(entry RepositoryEntry) (SourceTree, string, error) {
+	ctx := context.Background()
+	repository := fmt.Sprintf("%s://%s/api/v1/repos/%s/%s", p.Scheme, entry.Host, url.PathEscape(entry.Owner), url.PathEscape(entry.Repo))
+	resolve := func() (string, error) {
+		return p.resolveCommit(ctx, repository, entry.Ref)
+	}
+	download := func(sha string) (string, error) {
+		return p.getFile(ctx, repository+"/archive/"+url.PathEscape(sha)+".zip")
+	}
+	return fetchArchive(p.Cache, p.Offline, entry.Host+"/"+entry.Owner, entry.Repo, entry.Ref, resolve, download)
+}
+
+// resolveCommit löst eine Ref wie beim GitHubClient auf: leer = Standardbranch, Datum = letzter Commit
+// des Standardbranches davor, sonst Branch, Tag oder SHA
+func (p *GiteaProvider) resolveCommit(ctx context.Context, repository, ref string) (string, error) {
+	// Die Commit-Liste ohne Statistiken und Dateien ist die günstigste Anfrage, die jede Ref auflöst
+	query := url.Values{"limit": {"1"}, "stat": {"false"}, "verification": {"false"}, "files": {"false"}}
+
+	before, isDate := parseRefDate(ref)
+	if ref == "" || isDate {
+		var r struct {
+			DefaultBranch string `json:"default_branch"`
+		}
+		if err := p.getJSON(ctx, repository, &r); err != nil {
+			return "", fmt.Errorf("konnte Repo nicht abrufen: %w", err)
+		}
+		ref = r.DefaultBranch
+		if isDate {
+			query.Set("until", before.UTC().Format(time.RFC3339))
+		}
+	}
+	query.Set("sha", ref)
+
+	var commits []struct {
+		SHA string `json:"sha"`
+	}
+	if err := p.getJSON(ctx, repository+"/commits?"+query.Encode(), &commits); err != nil {
+		return "", fmt.Errorf("konnte Commit nicht auflösen: %w", err)
+	}
+	if len(commits) == 0 {
+		return "", fmt.Errorf("no commit of %s at %s", repository, ref)
+	}
+	return commits[0].SHA, nil
+}
@@ -1,82 +1,327 @@
 package utils
 
 import (
-	"archive/zip"
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"io"
+	"log"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/go-github/v60/github"
 	"golang.org/x/oauth2"
 )
 
-// fetchGoFilesList lädt das gesamte Repository als ZIP herunter,
-// entpackt alle .go-Dateien und gibt deren Inhalte als []string zurück.
-func FetchGoFilesList(owner, repo, token string) ([]string, error) {
-	ctx := context.Background()
-	var client *github.Client
+// GitHubClient kapselt einen go-github Client, der von allen Workern gemeinsam genutzt wird.
+// Dadurch kennt der Client das Rate Limit aller parallelen Anfragen: Ist es erschöpft,
+// warten alle Worker gemeinsam bis zum Reset, anstatt weitere Anfragen zu verschwenden.
+// Vorübergehende Fehler (5xx, Netzwerkfehler, sekundäre Rate Limits) werden mit
+// exponentiellem Backoff wiederholt.
+type GitHubClient struct {
+	client *github.Client
+
+	// MaxAttempts ist die maximale Anzahl Versuche pro Anfrage
+	MaxAttempts int
+	// Backoff ist die Wartezeit vor der ersten Wiederholung; sie verdoppelt sich mit jedem Versuch
+	Backoff time.Duration
+	// MaxBackoff begrenzt die Wartezeit zwischen zwei Versuchen
+	MaxBackoff time.Duration
+	// Timeout begrenzt jeden Versuch einschließlich der Übertragung der Antwort, damit eine hängende
+	// Verbindung den Worker nicht blockiert; 0 = ohne Begrenzung
+	Timeout time.Duration
+
+	// Cache ist optional; ist er gesetzt, werden Archive zuerst dort gesucht und nach dem Download abgelegt
+	Cache *ArchiveCache
+	// Offline analysiert ausschließlich Archive aus dem Cache, ohne GitHub zu kontaktieren
+	Offline bool
+	// Host ist bei GitHub Enterprise der Host der Instanz, leer für github.com. Wie bei den anderen Providern
+	// steht er vor dem Owner im Cache, damit gleichnamige Repositories verschiedener Instanzen getrennt bleiben.
+	Host string
+
+	mu           sync.Mutex
+	blockedUntil time.Time
+}
+
+// FetchError beschreibt einen Download, der auch nach allen Wiederholungen fehlgeschlagen ist
+type FetchError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *FetchError) Error() string {
+	return fmt.Sprintf("%v (after %d attempts)", e.Err, e.Attempts)
+}
+
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+func NewGitHubClient(token string) *GitHubClient {
+	return newGitHubClient(token, DefaultRequestTimeout)
+}
+
+// newGitHubClient erzeugt einen Client, dessen Anfragen nach timeout abgebrochen werden. go-github kopiert
+// den http.Client, sein Timeout lässt sich daher nur hier setzen.
+func newGitHubClient(token string, timeout time.Duration) *GitHubClient {
+	httpClient := &http.Client{}
 	if token != "" {
 		ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-		tc := oauth2.NewClient(ctx, ts)
-		client = github.NewClient(tc)
-	} else {
-		client = github.NewClient(nil)
+		httpClient = oauth2.NewClient(context.Background(), ts)
+	}
+	httpClient.Timeout = timeout
+	return &GitHubClient{
+		client:      github.NewClient(httpClient),
+		MaxAttempts: 5,
+		Backoff:     2 * time.Second,
+		MaxBackoff:  2 * time.Minute,
+		Timeout:     timeout,
 	}
+}
 
-	// Standardbranch abfragen (kostet 1 API-Call)
-	r, _, err := client.Repositories.Get(ctx, owner, repo)
+// SetBaseURL ändert die Adresse der GitHub-API, z.B. für GitHub Enterprise oder Tests
+func (c *GitHubClient) SetBaseURL(baseURL string) error {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	parsed, err := url.Parse(baseURL)
 	if err != nil {
-		return nil, fmt.Errorf("konnte Repo nicht abrufen: %w", err)
+		return err
 	}
-	defaultBranch := r.GetDefaultBranch()
+	c.client.BaseURL = parsed
+	return nil
+}
 
-	// ZIP-URL zusammensetzen
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/zipball/%s", owner, repo, defaultBranch)
-	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if token != "" {
-		req.Header.Set("Authorization", "token "+token)
+// FetchGoFilesList lädt das Repository als ZIP auf die Festplatte und gibt seine .go-Dateien sowie go.mod- und
+// go.work-Dateien als SourceTree zurück, zusammen mit dem SHA des analysierten Commits. Die Dateien werden erst
+// beim Lesen aus dem Baum entpackt; der Aufrufer muss den Baum schließen.
+// ref kann ein Branch, Tag, Commit-SHA oder Datum sein (siehe resolveCommit); ohne ref wird der Standardbranch geladen.
+// Mit Cache wird das Archiv eines Commits nur heruntergeladen, wenn es noch nicht im Cache liegt.
+func (c *GitHubClient) FetchGoFilesList(owner, repo, ref string) (SourceTree, string, error) {
+	ctx := context.Background()
+	resolve := func() (string, error) {
+		return c.resolveCommit(ctx, owner, repo, ref)
 	}
+	// ZIP in eine temporäre Datei herunterladen. Die Anfrage läuft über den go-github Client, damit dessen
+	// Rate-Limit-Tracking greift.
+	download := func(sha string) (string, error) {
+		var name string
+		err := c.do(ctx, func(ctx context.Context) (*github.Response, error) {
+			req, err := c.client.NewRequest("GET", fmt.Sprintf("repos/%s/%s/zipball/%s", owner, repo, sha), nil)
+			if err != nil {
+				return nil, err
+			}
+			resp, err := c.client.BareDo(ctx, req)
+			if err != nil {
+				return resp, err
+			}
+			defer resp.Body.Close()
 
-	// ZIP herunterladen
-	resp, err := client.Client().Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("konnte ZIP nicht laden: %w", err)
+			name, err = writeTempArchive(resp.Body)
+			if err != nil {
+				// Abgebrochene Übertragung (z.B. Timeout) wie einen Netzwerkfehler wiederholen
+				return nil, err
+			}
+			return resp, nil
+		})
+		return name, err
+	}
+	cacheOwner := owner
+	if c.Host != "" {
+		cacheOwner = c.Host + "/" + owner
 	}
-	defer resp.Body.Close()
+	return fetchArchive(c.Cache, c.Offline, cacheOwner, repo, ref, resolve, download)
+}
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("konnte ZIP nicht laden: %s", resp.Status)
+// FetchRepository implementiert SourceProvider
+func (c *GitHubClient) FetchRepository(entry RepositoryEntry) (SourceTree, string, error) {
+	return c.FetchGoFilesList(entry.Owner, entry.Repo, entry.Ref)
+}
+
+// resolveCommit löst eine Ref auf den SHA eines Commits auf:
+//   - leere Ref: aktueller Commit des Standardbranches (2 API-Calls)
+//   - Datum (YYYY-MM-DD oder RFC 3339): letzter Commit des Standardbranches vor diesem Zeitpunkt (2 API-Calls)
+//   - sonst Branch, Tag oder (abgekürzter) SHA (1 API-Call)
+func (c *GitHubClient) resolveCommit(ctx context.Context, owner, repo, ref string) (string, error) {
+	before, isDate := parseRefDate(ref)
+	if ref == "" || isDate {
+		var r *github.Repository
+		err := c.do(ctx, func(ctx context.Context) (*github.Response, error) {
+			var resp *github.Response
+			var err error
+			r, resp, err = c.client.Repositories.Get(ctx, owner, repo)
+			return resp, err
+		})
+		if err != nil {
+			return "", fmt.Errorf("konnte Repo nicht abrufen: %w", err)
+		}
+
+		if isDate {
+			var commits []*github.RepositoryCommit
+			err := c.do(ctx, func(ctx context.Context) (*github.Response, error) {
+				var resp *github.Response
+				var err error
+				commits, resp, err = c.client.Repositories.ListCommits(ctx, owner, repo, &github.CommitsListOptions{
+					SHA:         r.GetDefaultBranch(),
+					Until:       before,
+					ListOptions: github.ListOptions{PerPage: 1},
+				})
+				return resp, err
+			})
+			if err != nil {
+				return "", fmt.Errorf("konnte Commits nicht abrufen: %w", err)
+			}
+			if len(commits) == 0 {
+				return "", fmt.Errorf("no commit of %s/%s before %s", owner, repo, ref)
+			}
+			return commits[0].GetSHA(), nil
+		}
+		ref = r.GetDefaultBranch()
 	}
 
-	data, err := io.ReadAll(resp.Body)
+	var sha string
+	err := c.do(ctx, func(ctx context.Context) (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		sha, resp, err = c.client.Repositories.GetCommitSHA1(ctx, owner, repo, ref, "")
+		return resp, err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("konnte ZIP nicht lesen: %w", err)
+		return "", fmt.Errorf("konnte Commit nicht auflösen: %w", err)
 	}
+	return sha, nil
+}
 
-	// ZIP entpacken
-	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
-	if err != nil {
-		return nil, fmt.Errorf("konnte ZIP nicht entpacken: %w", err)
+// parseRefDate erkennt Refs, die einen Zeitpunkt angeben. Ein reines Datum steht für Mitternacht UTC,
+// "2024-01-01" wählt also den letzten Commit vor dem 1. Januar 2024.
+func parseRefDate(ref string) (time.Time, bool) {
+	if date, err := time.Parse(time.DateOnly, ref); err == nil {
+		return date, true
 	}
+	if date, err := time.Parse(time.RFC3339, ref); err == nil {
+		return date, true
+	}
+	return time.Time{}, false
+}
 
-	var files []string
-	for _, f := range zr.File {
-		if !f.FileInfo().IsDir() && strings.HasSuffix(f.Name, ".go") {
-			rc, err := f.Open()
-			if err != nil {
-				continue
-			}
-			content, err := io.ReadAll(rc)
-			rc.Close()
-			if err != nil {
-				continue
-			}
-			files = append(files, string(content))
+// do führt eine Anfrage aus und beachtet dabei das Rate Limit:
+//   - Ist das primäre Rate Limit erschöpft (X-RateLimit-Remaining: 0), wird bis X-RateLimit-Reset gewartet
+//   - Bei 403/429 mit Retry-After (sekundäres Rate Limit) wird die angegebene Zeit gewartet
+//   - 5xx-Antworten und Netzwerkfehler werden mit exponentiellem Backoff wiederholt
+//
+// Andere Fehler (z.B. 404) werden sofort zurückgegeben. Schlägt die Anfrage endgültig fehl,
+// ist der Fehler ein *FetchError mit der Anzahl der Versuche. request erhält einen Kontext, der nach
+// c.Timeout abläuft; ein abgelaufener Versuch wird wie ein Netzwerkfehler wiederholt.
+func (c *GitHubClient) do(ctx context.Context, request func(ctx context.Context) (*github.Response, error)) error {
+	maxAttempts := max(c.MaxAttempts, 1)
+
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		c.waitForRateLimit(ctx)
+
+		attemptCtx, cancel := withTimeout(ctx, c.Timeout)
+		var resp *github.Response
+		resp, err = request(attemptCtx)
+		cancel()
+		if resp != nil && resp.Rate.Limit > 0 && resp.Rate.Remaining == 0 {
+			c.blockUntil(resp.Rate.Reset.Time)
+		}
+		if err == nil {
+			return nil
+		}
+
+		wait, retry := c.retryDelay(resp, err, attempt)
+		if !retry || attempt == maxAttempts {
+			return &FetchError{Attempts: attempt, Err: err}
+		}
+
+		log.Printf("GitHub request failed (attempt %d/%d), retrying in %s: %v", attempt, maxAttempts, wait.Round(time.Millisecond), err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return &FetchError{Attempts: attempt, Err: ctx.Err()}
+		}
+	}
+	return &FetchError{Attempts: maxAttempts, Err: err}
+}
+
+// retryDelay entscheidet, ob ein Fehler vorübergehend ist, und wie lange vor dem nächsten Versuch gewartet wird
+func (c *GitHubClient) retryDelay(resp *github.Response, err error, attempt int) (time.Duration, bool) {
+	backoff := c.Backoff << (attempt - 1)
+	if c.MaxBackoff > 0 && backoff > c.MaxBackoff {
+		backoff = c.MaxBackoff
+	}
+
+	// Primäres Rate Limit: Warten übernimmt waitForRateLimit
+	var rateLimitErr *github.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		c.blockUntil(rateLimitErr.Rate.Reset.Time)
+		return 0, true
+	}
+
+	// Sekundäres Rate Limit
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		if abuseErr.RetryAfter != nil {
+			return *abuseErr.RetryAfter, true
+		}
+		return backoff, true
+	}
+
+	if resp == nil || resp.Response == nil {
+		// Keine Antwort erhalten (Netzwerkfehler, Timeout)
+		return backoff, true
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusForbidden:
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return retryAfter, true
 		}
+		return backoff, resp.StatusCode == http.StatusTooManyRequests
+	case resp.StatusCode >= 500:
+		return backoff, true
 	}
+	return 0, false
+}
 
-	return files, nil
+// parseRetryAfter liest den Retry-After Header (Sekunden oder HTTP-Datum)
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+func (c *GitHubClient) blockUntil(reset time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if reset.After(c.blockedUntil) {
+		c.blockedUntil = reset
+	}
+}
+
+// waitForRateLimit blockiert, solange das gemeinsame Rate Limit erschöpft ist
+func (c *GitHubClient) waitForRateLimit(ctx context.Context) {
+	c.mu.Lock()
+	wait := time.Until(c.blockedUntil)
+	c.mu.Unlock()
+	if wait <= 0 {
+		return
+	}
+
+	log.Printf("GitHub rate limit exhausted, waiting %s until reset", wait.Round(time.Second))
+	select {
+	case <-time.After(wait):
+	case <-ctx.Done():
+	}
 }
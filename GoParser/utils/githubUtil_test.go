@@ -0,0 +1,298 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testGitHub ist ein lokaler Ersatz für api.github.com
+type testGitHub struct {
+	client      *GitHubClient
+	requests    int32 // Anfragen an /repos/owner/repo
+	zipRequests int32 // Downloads des Zipballs
+
+	commitListQuery url.Values // Parameter der letzten Abfrage der Commit-Liste
+}
+
+// newTestGitHub startet einen lokalen Ersatz für api.github.com. Die ersten failures Anfragen
+// an /repos/owner/repo werden mit fail beantwortet, danach liefert der Server Repository, Commit und Zipball.
+func newTestGitHub(t *testing.T, failures int32, fail func(w http.ResponseWriter)) *testGitHub {
+	t.Helper()
+
+	var zipBuffer bytes.Buffer
+	zw := zip.NewWriter(&zipBuffer)
+	for name, content := range map[string]string{
+		"owner-repo-abc123/go.mod":      "module example.com/repo\n",
+		"owner-repo-abc123/main.go":     "package main\n",
+		"owner-repo-abc123/README.md":   "# repo\n",
+		"owner-repo-abc123/pkg/list.go": "package pkg\n",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprint(w, content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	gh := &testGitHub{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&gh.requests, 1) <= failures {
+			fail(w)
+			return
+		}
+		fmt.Fprint(w, `{"name": "repo", "default_branch": "main"}`)
+	})
+	mux.HandleFunc("/repos/owner/repo/commits/main", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "abc123")
+	})
+	mux.HandleFunc("/repos/owner/repo/commits/v1.0", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "def456")
+	})
+	mux.HandleFunc("/repos/owner/repo/commits", func(w http.ResponseWriter, r *http.Request) {
+		gh.commitListQuery = r.URL.Query()
+		fmt.Fprint(w, `[{"sha": "789abc"}]`)
+	})
+	zipball := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&gh.zipRequests, 1)
+		w.Write(zipBuffer.Bytes())
+	}
+	mux.HandleFunc("/repos/owner/repo/zipball/main", zipball)
+	mux.HandleFunc("/repos/owner/repo/zipball/abc123", zipball)
+	mux.HandleFunc("/repos/owner/repo/zipball/def456", zipball)
+	mux.HandleFunc("/repos/owner/repo/zipball/789abc", zipball)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	gh.client = NewGitHubClient("")
+	if err := gh.client.SetBaseURL(server.URL); err != nil {
+		t.Fatal(err)
+	}
+	gh.client.Backoff = time.Millisecond
+	return gh
+}
+
+func TestFetchGoFilesListRetriesServerErrors(t *testing.T) {
+	gh := newTestGitHub(t, 2, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	files, _, err := readTree(gh.client.FetchGoFilesList("owner", "repo", ""))
+	if err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if gh.requests != 3 {
+		t.Errorf("got %d requests, want 3", gh.requests)
+	}
+	if len(files) != 3 {
+		t.Errorf("got %d files, want 3 (go.mod, main.go, pkg/list.go)", len(files))
+	}
+	for _, f := range files {
+		if f.Path == "owner-repo-abc123/main.go" {
+			t.Errorf("archive root was not stripped from %s", f.Path)
+		}
+	}
+}
+
+func TestFetchGoFilesListWaitsForRateLimitReset(t *testing.T) {
+	gh := newTestGitHub(t, 1, func(w http.ResponseWriter) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(time.Now().Add(2*time.Second).Unix()))
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "API rate limit exceeded for 127.0.0.1."}`)
+	})
+
+	start := time.Now()
+	if _, _, err := readTree(gh.client.FetchGoFilesList("owner", "repo", "")); err != nil {
+		t.Fatalf("expected success after rate limit reset, got %v", err)
+	}
+	if gh.requests != 2 {
+		t.Errorf("got %d requests, want 2", gh.requests)
+	}
+	// Der Reset wird in ganzen Sekunden angegeben, daher mindestens eine Sekunde Wartezeit
+	if time.Since(start) < 900*time.Millisecond {
+		t.Errorf("client did not wait for the rate limit reset")
+	}
+}
+
+func TestFetchGoFilesListHonoursRetryAfter(t *testing.T) {
+	gh := newTestGitHub(t, 1, func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	// Ohne Retry-After würde sofort wiederholt werden
+	gh.client.Backoff = 0
+
+	start := time.Now()
+	if _, _, err := readTree(gh.client.FetchGoFilesList("owner", "repo", "")); err != nil {
+		t.Fatalf("expected success after Retry-After, got %v", err)
+	}
+	if gh.requests != 2 {
+		t.Errorf("got %d requests, want 2", gh.requests)
+	}
+	if time.Since(start) < 900*time.Millisecond {
+		t.Errorf("client did not honour Retry-After")
+	}
+}
+
+func TestFetchGoFilesListReportsFinalFailure(t *testing.T) {
+	gh := newTestGitHub(t, 100, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	gh.client.MaxAttempts = 3
+
+	_, _, err := readTree(gh.client.FetchGoFilesList("owner", "repo", ""))
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) {
+		t.Fatalf("expected *FetchError, got %v", err)
+	}
+	if fetchErr.Attempts != 3 || gh.requests != 3 {
+		t.Errorf("got %d attempts and %d requests, want 3", fetchErr.Attempts, gh.requests)
+	}
+}
+
+func TestFetchGoFilesListDoesNotRetryNotFound(t *testing.T) {
+	gh := newTestGitHub(t, 100, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	_, _, err := readTree(gh.client.FetchGoFilesList("owner", "repo", ""))
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) || fetchErr.Attempts != 1 {
+		t.Fatalf("expected *FetchError after 1 attempt, got %v", err)
+	}
+	if gh.requests != 1 {
+		t.Errorf("got %d requests, want 1", gh.requests)
+	}
+}
+
+func TestFetchGoFilesListUsesArchiveCache(t *testing.T) {
+	gh := newTestGitHub(t, 0, nil)
+	gh.client.Cache = NewArchiveCache(t.TempDir())
+
+	for i := 0; i < 2; i++ {
+		files, _, err := readTree(gh.client.FetchGoFilesList("owner", "repo", ""))
+		if err != nil {
+			t.Fatalf("fetch %d failed: %v", i, err)
+		}
+		if len(files) != 3 {
+			t.Errorf("fetch %d returned %d files, want 3", i, len(files))
+		}
+	}
+	if gh.zipRequests != 1 {
+		t.Errorf("zipball was downloaded %d times, want 1", gh.zipRequests)
+	}
+	name, ok := gh.client.Cache.Path("owner", "repo", "abc123")
+	if !ok {
+		t.Fatalf("archive is not cached under its commit SHA")
+	}
+	// Der Cache enthält nur die Quelldateien, nicht README.md
+	cached, err := zip.OpenReader(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cached.File) != 3 {
+		t.Errorf("cached archive has %d entries, want 3 source files", len(cached.File))
+	}
+	cached.Close()
+
+	// Offline wird ausschließlich der Cache verwendet
+	offline := NewGitHubClient("")
+	offline.Cache = gh.client.Cache
+	offline.Offline = true
+	if err := offline.SetBaseURL("http://127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	files, _, err := readTree(offline.FetchGoFilesList("owner", "repo", ""))
+	if err != nil || len(files) != 3 {
+		t.Errorf("offline fetch returned %d files and error %v, want 3 files", len(files), err)
+	}
+	if _, _, err := readTree(offline.FetchGoFilesList("owner", "unknown", "")); err == nil {
+		t.Errorf("expected error for repository missing in the cache")
+	}
+
+	// Ein gleichnamiges Repository einer GitHub-Enterprise-Instanz darf nicht das Archiv von github.com erhalten
+	offline.Host = "github.example.com"
+	if _, _, err := readTree(offline.FetchGoFilesList("owner", "repo", "")); err == nil {
+		t.Errorf("GitHub Enterprise repository was served from the cache entry of github.com")
+	}
+}
+
+func TestFetchGoFilesListResolvesRef(t *testing.T) {
+	gh := newTestGitHub(t, 0, nil)
+	gh.client.Cache = NewArchiveCache(t.TempDir())
+
+	for _, test := range []struct {
+		ref  string
+		want string
+	}{
+		{"", "abc123"},
+		{"v1.0", "def456"},
+		{"2024-01-01", "789abc"},
+	} {
+		files, sha, err := readTree(gh.client.FetchGoFilesList("owner", "repo", test.ref))
+		if err != nil {
+			t.Fatalf("fetch of ref %q failed: %v", test.ref, err)
+		}
+		if sha != test.want || len(files) != 3 {
+			t.Errorf("ref %q resolved to %s with %d files, want %s with 3 files", test.ref, sha, len(files), test.want)
+		}
+	}
+	if got := gh.commitListQuery.Get("until"); got != "2024-01-01T00:00:00Z" {
+		t.Errorf("commits were listed until %q, want 2024-01-01T00:00:00Z", got)
+	}
+	if got := gh.commitListQuery.Get("sha"); got != "main" {
+		t.Errorf("commits were listed on %q, want the default branch", got)
+	}
+
+	// Offline werden gepinnte Refs über den Cache aufgelöst
+	gh.client.Offline = true
+	if _, sha, err := readTree(gh.client.FetchGoFilesList("owner", "repo", "v1.0")); err != nil || sha != "def456" {
+		t.Errorf("offline fetch of v1.0 returned %s and error %v, want def456", sha, err)
+	}
+}
+
+func TestFetchGoFilesListAbortsStalledDownload(t *testing.T) {
+	var zipRequests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/commits/main", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "abc123")
+	})
+	mux.HandleFunc("/repos/owner/repo/zipball/abc123", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&zipRequests, 1)
+		// Die Übertragung beginnt und bleibt dann hängen, bis der Client abbricht
+		w.Write([]byte("PK"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := newGitHubClient("", 100*time.Millisecond)
+	if err := client.SetBaseURL(server.URL); err != nil {
+		t.Fatal(err)
+	}
+	client.MaxAttempts, client.Backoff = 2, time.Millisecond
+
+	_, _, err := client.FetchGoFilesList("owner", "repo", "main")
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) || fetchErr.Attempts != 2 {
+		t.Fatalf("got %v, want a FetchError after 2 attempts", err)
+	}
+	if zipRequests != 2 {
+		t.Errorf("got %d downloads, want 2", zipRequests)
+	}
+}
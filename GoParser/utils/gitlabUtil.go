@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// GitLabProvider lädt Repositories über die REST-API (v4) von gitlab.com oder einer selbst gehosteten Instanz.
+// Der Host kommt aus dem RepositoryEntry, ein Provider kann also mehrere Instanzen bedienen.
+type GitLabProvider struct {
+	httpSource
+
+	// Scheme der API-Adresse, standardmäßig "https"
+	Scheme string
+	// Cache ist optional; ist er gesetzt, werden Archive zuerst dort gesucht und nach dem Download abgelegt
+	Cache *ArchiveCache
+	// Offline analysiert ausschließlich Archive aus dem Cache
+	Offline bool
+}
+
+// NewGitLabProvider erzeugt einen Provider; token ist optional und wird für private Projekte benötigt
+func NewGitLabProvider(token string) *GitLabProvider {
+	p := &GitLabProvider{httpSource: newHTTPSource("GitLab"), Scheme: "https"}
+	if token != "" {
+		p.header.Set("PRIVATE-TOKEN", token)
+	}
+	return p
+}
+
+// FetchRepository lädt das Archiv des Commits, zu dem entry.Ref aufgelöst wird (siehe resolveCommit)
+func (p *GitLabProvider) FetchRepository(entry RepositoryEntry) (SourceTree, string, error) {
+	ctx := context.Background()
+	project := p.projectURL(entry)
+	resolve := func() (string, error) {
+		return p.resolveCommit(ctx, project, entry.Ref)
+	}
+	download := func(sha string) (string, error) {
+		return p.getFile(ctx, project+"/repository/archive.zip?sha="+url.QueryEscape(sha))
+	}
+	return fetchArchive(p.Cache, p.Offline, entry.Host+"/"+entry.Owner, entry.Repo, entry.Ref, resolve, download)
+}
+
+// projectURL liefert die API-Adresse des Projekts; GitLab identifiziert es über den URL-kodierten Pfad
+func (p *GitLabProvider) projectURL(entry RepositoryEntry) string {
+	return fmt.Sprintf("%s://%s/api/v4/projects/%s", p.Scheme, entry.Host, url.PathEscape(entry.Owner+"/"+entry.Repo))
+}
+
+// resolveCommit löst eine Ref wie beim GitHubClient auf: leer = Standardbranch, Datum = letzter Commit
+// des Standardbranches davor, sonst Branch, Tag oder SHA
+func (p *GitLabProvider) resolveCommit(ctx context.Context, project, ref string) (string, error) {
+	before, isDate := parseRefDate(ref)
+	if ref == "" || isDate {
+		var repository struct {
+			DefaultBranch string `json:"default_branch"`
+		}
+		if err := p.getJSON(ctx, project, &repository); err != nil {
+			return "", fmt.Errorf("konnte Projekt nicht abrufen: %w", err)
+		}
+
+		if isDate {
+			query := url.Values{"ref_name": {repository.DefaultBranch}, "until": {before.UTC().Format(time.RFC3339)}, "per_page": {"1"}}
+			var commits []struct {
+				ID string `json:"id"`
+			}
+			if err := p.getJSON(ctx, project+"/repository/commits?"+query.Encode(), &commits); err != nil {
+				return "", fmt.Errorf("konnte Commits nicht abrufen: %w", err)
+			}
+			if len(commits) == 0 {
+				return "", fmt.Errorf("no commit of %s before %s", project, ref)
+			}
+			return commits[0].ID, nil
+		}
+		ref = repository.DefaultBranch
+	}
+
+	var commit struct {
+		ID string `json:"id"`
+	}
+	if err := p.getJSON(ctx, project+"/repository/commits/"+url.PathEscape(ref), &commit); err != nil {
+		return "", fmt.Errorf("konnte Commit nicht auflösen: %w", err)
+	}
+	return commit.ID, nil
+}
@@ -0,0 +1,188 @@
+package utils
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// GoModFile enthält die für die Analyse relevanten Angaben einer go.mod-Datei
+type GoModFile struct {
+	Module    string
+	GoVersion string
+	Toolchain string
+	Require   map[string]string         // Modulpfad -> Version
+	Replace   map[string]module.Version // Modulpfad -> Ersatz der geforderten Version; ohne Version bei lokalen Verzeichnissen
+}
+
+// ParseGoMod liest die Direktiven module, go, toolchain, require und replace aus dem Inhalt einer go.mod-Datei.
+// Dateien, die der go-Befehl ablehnen würde (z.B. mit Direktiven einer neueren Go-Version), werden wie die go.mod
+// einer Abhängigkeit gelesen, also ohne toolchain und replace; erst wenn auch das scheitert, gibt es einen Fehler.
+func ParseGoMod(content string) (GoModFile, error) {
+	file, err := modfile.Parse("go.mod", []byte(content), nil)
+	if err != nil {
+		var laxErr error
+		if file, laxErr = modfile.ParseLax("go.mod", []byte(content), nil); laxErr != nil {
+			return GoModFile{}, err
+		}
+	}
+
+	modFile := GoModFile{Require: make(map[string]string), Replace: make(map[string]module.Version)}
+	if file.Module != nil {
+		modFile.Module = file.Module.Mod.Path
+	}
+	if file.Go != nil {
+		modFile.GoVersion = file.Go.Version
+	}
+	if file.Toolchain != nil {
+		modFile.Toolchain = file.Toolchain.Name
+	}
+	for _, require := range file.Require {
+		modFile.Require[require.Mod.Path] = require.Mod.Version
+	}
+	modFile.Replace = replacements(file.Replace, modFile.Require)
+	return modFile, nil
+}
+
+// ParseGoWork liest die Direktiven go und toolchain aus dem Inhalt einer go.work-Datei
+func ParseGoWork(content string) (GoModFile, error) {
+	file, err := modfile.ParseWork("go.work", []byte(content), nil)
+	if err != nil {
+		return GoModFile{}, err
+	}
+	modFile := GoModFile{}
+	if file.Go != nil {
+		modFile.GoVersion = file.Go.Version
+	}
+	if file.Toolchain != nil {
+		modFile.Toolchain = file.Toolchain.Name
+	}
+	return modFile, nil
+}
+
+// replacements ermittelt für jedes Modul den Ersatz, den der go-Befehl für die geforderte Version verwendet:
+// Eine Ersetzung für genau diese Version hat Vorrang vor einer für alle Versionen des Moduls.
+func replacements(replace []*modfile.Replace, require map[string]string) map[string]module.Version {
+	result := make(map[string]module.Version)
+	for _, r := range replace {
+		if r.Old.Version == "" {
+			result[r.Old.Path] = r.New
+		}
+	}
+	for _, r := range replace {
+		if r.Old.Version != "" && r.Old.Version == require[r.Old.Path] {
+			result[r.Old.Path] = r.New
+		}
+	}
+	return result
+}
+
+// GoLanguageVersion kürzt die Version einer go-Direktive auf die Sprachversion, z.B. "1.21.0" und "1.21rc1" auf "1.21".
+// Seit Go 1.21 darf die go-Direktive eine Patch-Version enthalten; für die Sprache zählen nur Major und Minor.
+// Nicht lesbare Versionen werden unverändert zurückgegeben.
+func GoLanguageVersion(version string) string {
+	major, minor, ok := parseGoLanguageVersion(version)
+	if !ok {
+		return version
+	}
+	return strconv.Itoa(major) + "." + strconv.Itoa(minor)
+}
+
+// GenericsAvailable gibt an, ob Module mit dieser go-Direktive Generics verwenden dürfen (ab Go 1.18).
+// Fehlt die Direktive, nimmt der go-Befehl Go 1.16 an, also stehen Generics nicht zur Verfügung.
+func GenericsAvailable(version string) bool {
+	return CompareGoLanguageVersions(version, "1.18") >= 0
+}
+
+// CompareGoLanguageVersions vergleicht die Sprachversionen zweier go-Direktiven numerisch ("1.9" < "1.18").
+// Leere und nicht lesbare Versionen sind kleiner als alle anderen.
+func CompareGoLanguageVersions(a, b string) int {
+	majorA, minorA, okA := parseGoLanguageVersion(a)
+	majorB, minorB, okB := parseGoLanguageVersion(b)
+	switch {
+	case !okA || !okB:
+		return cmp.Compare(boolToInt(okA), boolToInt(okB))
+	case majorA != majorB:
+		return cmp.Compare(majorA, majorB)
+	default:
+		return cmp.Compare(minorA, minorB)
+	}
+}
+
+// parseGoLanguageVersion liest Major und Minor einer Version wie "1.21", "1.21.0", "1.21rc1" oder "go1.21"
+func parseGoLanguageVersion(version string) (major, minor int, ok bool) {
+	version = strings.TrimPrefix(version, "go")
+	majorPart, rest, found := strings.Cut(version, ".")
+	if !found {
+		return 0, 0, false
+	}
+	end := 0
+	for end < len(rest) && rest[end] >= '0' && rest[end] <= '9' {
+		end++
+	}
+	major, errMajor := strconv.Atoi(majorPart)
+	minor, errMinor := strconv.Atoi(rest[:end])
+	if errMajor != nil || errMinor != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// DefaultModCache liefert das Modul-Cache-Verzeichnis wie der go-Befehl: GOMODCACHE, sonst GOPATH/pkg/mod
+// (erster Eintrag von GOPATH), sonst $HOME/go/pkg/mod. Existiert es nicht, wird "" geliefert.
+func DefaultModCache() string {
+	dir := os.Getenv("GOMODCACHE")
+	if dir == "" {
+		gopath := filepath.SplitList(os.Getenv("GOPATH"))
+		if len(gopath) > 0 && gopath[0] != "" {
+			dir = filepath.Join(gopath[0], "pkg", "mod")
+		} else if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, "go", "pkg", "mod")
+		}
+	}
+	if info, err := os.Stat(dir); dir == "" || err != nil || !info.IsDir() {
+		return ""
+	}
+	return dir
+}
+
+// EscapeModulePath kodiert einen Modulpfad wie im Modul-Cache und im Proxy-Protokoll: Großbuchstaben werden
+// zu "!" und dem Kleinbuchstaben ("Azure" -> "!azure"), da viele Dateisysteme Groß- und Kleinschreibung nicht
+// unterscheiden. Pfade, die kein gültiges Verzeichnis im Cache ergeben, werden abgelehnt.
+func EscapeModulePath(modulePath string) (string, error) {
+	if modulePath == "" || strings.Contains(modulePath, "!") || strings.HasPrefix(modulePath, "/") || strings.Contains(modulePath, "..") {
+		return "", fmt.Errorf("invalid module path %q", modulePath)
+	}
+	for _, r := range modulePath {
+		if r >= unicode.MaxASCII {
+			return "", fmt.Errorf("invalid module path %q", modulePath)
+		}
+	}
+	return escapeModuleVersion(modulePath), nil
+}
+
+func escapeModuleVersion(s string) string {
+	var escaped strings.Builder
+	for _, r := range s {
+		if 'A' <= r && r <= 'Z' {
+			escaped.WriteByte('!')
+			r += 'a' - 'A'
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
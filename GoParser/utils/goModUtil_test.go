@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"maps"
+	"testing"
+
+	"golang.org/x/mod/module"
+)
+
+func TestParseGoModToolchain(t *testing.T) {
+	modFile, err := ParseGoMod("module example.com/m // Kommentar\n\ngo 1.22.0\n\ntoolchain go1.22.3\n\nrequire (\n\tgolang.org/x/exp v0.0.0-20240112132812-db7319d0e0e3\n)\n")
+	if err != nil {
+		t.Fatalf("failed to parse go.mod: %v", err)
+	}
+	if modFile.Module != "example.com/m" || modFile.GoVersion != "1.22.0" || modFile.Toolchain != "go1.22.3" {
+		t.Errorf("got module=%q go=%q toolchain=%q, want example.com/m, 1.22.0 and go1.22.3", modFile.Module, modFile.GoVersion, modFile.Toolchain)
+	}
+	if modFile.Require["golang.org/x/exp"] != "v0.0.0-20240112132812-db7319d0e0e3" {
+		t.Errorf("require block not parsed: %v", modFile.Require)
+	}
+}
+
+func TestParseGoModReplace(t *testing.T) {
+	modFile, err := ParseGoMod(`module example.com/m
+
+go 1.22
+
+require (
+	example.com/a v1.0.0
+	example.com/b v1.2.0
+	example.com/c v1.0.0
+	example.com/d v1.0.0
+)
+
+replace example.com/a => example.com/fork/a v1.1.0
+
+replace (
+	example.com/b => example.com/fork/b v1.0.0
+	example.com/b v1.2.0 => example.com/fork/b v1.3.0
+	example.com/c v0.9.0 => example.com/fork/c v1.0.0
+	example.com/d => ../d
+)
+`)
+	if err != nil {
+		t.Fatalf("failed to parse go.mod: %v", err)
+	}
+
+	want := map[string]module.Version{
+		"example.com/a": {Path: "example.com/fork/a", Version: "v1.1.0"},
+		"example.com/b": {Path: "example.com/fork/b", Version: "v1.3.0"}, // die Ersetzung der geforderten Version hat Vorrang
+		"example.com/d": {Path: "../d"},
+	}
+	if !maps.Equal(modFile.Replace, want) {
+		t.Errorf("Replace = %v, want %v", modFile.Replace, want)
+	}
+}
+
+func TestParseGoModFallsBackToLaxParsing(t *testing.T) {
+	// Unbekannte Direktiven (z.B. aus einer neueren Go-Version) lehnt der go-Befehl ab; module und go werden trotzdem gelesen
+	modFile, err := ParseGoMod("module example.com/m\n\ngo 1.21\n\nfuture example.com/a\n\nreplace example.com/a => ../a\n")
+	if err != nil {
+		t.Fatalf("failed to parse go.mod: %v", err)
+	}
+	if modFile.Module != "example.com/m" || modFile.GoVersion != "1.21" || len(modFile.Replace) != 0 {
+		t.Errorf("got module=%q go=%q replace=%v, want example.com/m, 1.21 and no replacements", modFile.Module, modFile.GoVersion, modFile.Replace)
+	}
+
+	if _, err := ParseGoMod("module\n"); err == nil {
+		t.Error("expected an error for a module directive without path")
+	}
+}
+
+func TestParseGoWork(t *testing.T) {
+	modFile, err := ParseGoWork("go 1.23.1\n\ntoolchain go1.23.4\n\nuse (\n\t./a\n\t./b\n)\n")
+	if err != nil {
+		t.Fatalf("failed to parse go.work: %v", err)
+	}
+	if modFile.GoVersion != "1.23.1" || modFile.Toolchain != "go1.23.4" || modFile.Module != "" {
+		t.Errorf("got module=%q go=%q toolchain=%q, want no module, 1.23.1 and go1.23.4", modFile.Module, modFile.GoVersion, modFile.Toolchain)
+	}
+}
+
+func TestGoLanguageVersion(t *testing.T) {
+	tests := []struct {
+		version, language string
+		generics          bool
+	}{
+		{"", "", false},
+		{"1.13", "1.13", false},
+		{"1.17", "1.17", false},
+		{"1.18", "1.18", true},
+		{"1.21.0", "1.21", true},
+		{"1.21rc1", "1.21", true},
+		{"1.9", "1.9", false},
+		{"go1.22.3", "1.22", true},
+		{"latest", "latest", false},
+	}
+
+	for _, tt := range tests {
+		if got := GoLanguageVersion(tt.version); got != tt.language {
+			t.Errorf("GoLanguageVersion(%q) = %q, want %q", tt.version, got, tt.language)
+		}
+		if got := GenericsAvailable(tt.version); got != tt.generics {
+			t.Errorf("GenericsAvailable(%q) = %t, want %t", tt.version, got, tt.generics)
+		}
+	}
+
+	if CompareGoLanguageVersions("1.9", "1.18") >= 0 {
+		t.Errorf("1.9 must be older than 1.18")
+	}
+	if CompareGoLanguageVersions("", "1.9") >= 0 {
+		t.Errorf("a missing go directive must sort before every version")
+	}
+}
+
+func TestEscapeModulePath(t *testing.T) {
+	tests := []struct {
+		modulePath, escaped string
+		valid               bool
+	}{
+		{"github.com/Azure/azure-sdk-for-go", "github.com/!azure/azure-sdk-for-go", true},
+		{"golang.org/x/exp", "golang.org/x/exp", true},
+		{"", "", false},
+		{"example.com/!azure", "", false},
+		{"/abs/path", "", false},
+		{"example.com/../escape", "", false},
+		{"example.com/straße", "", false},
+	}
+
+	for _, tt := range tests {
+		escaped, err := EscapeModulePath(tt.modulePath)
+		if (err == nil) != tt.valid || escaped != tt.escaped {
+			t.Errorf("EscapeModulePath(%q) = %q, %v, want %q (valid %t)", tt.modulePath, escaped, err, tt.escaped, tt.valid)
+		}
+	}
+}
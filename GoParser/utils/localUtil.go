@@ -6,10 +6,12 @@ import (
 	"strings"
 )
 
-// fetchLocalGoFiles durchläuft ein lokales Verzeichnis rekursiv
-// und sammelt alle .go-Dateien (außer vendor, .git, etc.)
-func FetchLocalGoFiles(projectPath string) ([]string, error) {
-	var files []string
+// OpenLocalTree durchläuft ein lokales Verzeichnis rekursiv und sammelt die Pfade aller .go-Dateien
+// sowie go.mod- und go.work-Dateien (außer .git, etc.) relativ zum Projekt. Die Dateien selbst werden
+// erst beim Lesen aus dem Baum geladen.
+// vendor-Verzeichnisse werden nicht übersprungen, sondern wie bei GitHub als vendored eingeordnet.
+func OpenLocalTree(projectPath string) (SourceTree, error) {
+	tree := &dirTree{base: projectPath}
 
 	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -19,23 +21,27 @@ func FetchLocalGoFiles(projectPath string) ([]string, error) {
 		// Überspringe spezielle Verzeichnisse
 		if info.IsDir() {
 			dirName := info.Name()
-			if dirName == "vendor" || dirName == ".git" || dirName == "node_modules" || strings.HasPrefix(dirName, ".") {
+			if path != projectPath && (dirName == ".git" || dirName == "node_modules" || strings.HasPrefix(dirName, ".")) {
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
-		// Nur .go-Dateien sammeln
-		if strings.HasSuffix(path, ".go") {
-			content, err := os.ReadFile(path)
+		// Nur .go-Dateien, go.mod und go.work sammeln (go.mod wird für die Typprüfung und die Go-Version benötigt)
+		if isSourceFile(info.Name()) {
+			relPath, err := filepath.Rel(projectPath, path)
 			if err != nil {
 				return err
 			}
-			files = append(files, string(content))
+			tree.paths = append(tree.paths, filepath.ToSlash(relPath))
 		}
 
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	return files, err
+	SortSourcePaths(tree.paths)
+	return tree, nil
 }
@@ -0,0 +1,348 @@
+package utils
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// defaultGOPROXY entspricht der Voreinstellung des go-Befehls
+const defaultGOPROXY = "https://proxy.golang.org,direct"
+
+// moduleProxy ist ein Eintrag der GOPROXY-Liste
+type moduleProxy struct {
+	url string // "direct", "off" oder die Adresse des Proxys (https://, http:// oder file://)
+	// fallbackOnError ist gesetzt, wenn der Eintrag mit "|" abgetrennt ist: Dann wird bei jedem Fehler
+	// der nächste Proxy versucht, bei "," nur, wenn das Modul nicht gefunden wurde (404/410)
+	fallbackOnError bool
+	// modCache kennzeichnet den lokalen Modul-Cache. Er enthält nur die bisher geladenen Versionen und
+	// wird daher online nur für unveränderliche Dateien (.info und .zip einer Version) verwendet.
+	modCache bool
+}
+
+// ModuleProxyProvider lädt veröffentlichte Module über das GOPROXY-Protokoll (/@v/list, /@v/<version>.zip).
+// Wie beim go-Befehl werden die Proxys aus GOPROXY der Reihe nach gefragt. Davor wird der lokale Modul-Cache
+// (GOMODCACHE/cache/download) wie ein file://-Proxy gelesen, sodass bereits geladene Module auch offline
+// analysiert werden können. Direkter Zugriff auf das Versionskontrollsystem ("direct") wird nicht unterstützt.
+type ModuleProxyProvider struct {
+	httpSource
+
+	proxies []moduleProxy
+
+	// Cache ist optional; Modul-Archive werden darin unter ihrer Version abgelegt
+	Cache *ArchiveCache
+	// Offline verwendet nur file://-Proxys, den Modul-Cache und den Archiv-Cache
+	Offline bool
+}
+
+// NewModuleProxyProvider liest die Proxys aus goproxy (leer = Voreinstellung des go-Befehls) und stellt
+// den Modul-Cache unter modCache voran (leer = kein Modul-Cache)
+func NewModuleProxyProvider(goproxy, modCache string) (*ModuleProxyProvider, error) {
+	proxies, err := parseGOPROXY(goproxy)
+	if err != nil {
+		return nil, err
+	}
+	if modCache != "" {
+		cacheProxy := moduleProxy{url: (&url.URL{Scheme: "file", Path: filepath.ToSlash(filepath.Join(modCache, "cache", "download"))}).String(), fallbackOnError: true, modCache: true}
+		proxies = append([]moduleProxy{cacheProxy}, proxies...)
+	}
+	return &ModuleProxyProvider{httpSource: newHTTPSource("Module proxy"), proxies: proxies}, nil
+}
+
+// parseGOPROXY zerlegt eine GOPROXY-Liste wie "https://proxy.golang.org,direct" in ihre Einträge
+func parseGOPROXY(goproxy string) ([]moduleProxy, error) {
+	if goproxy == "" {
+		goproxy = defaultGOPROXY
+	}
+
+	var proxies []moduleProxy
+	for goproxy != "" {
+		entry := goproxy
+		fallbackOnError := false
+		if i := strings.IndexAny(goproxy, ",|"); i >= 0 {
+			entry, fallbackOnError, goproxy = goproxy[:i], goproxy[i] == '|', goproxy[i+1:]
+		} else {
+			goproxy = ""
+		}
+		entry = strings.TrimSpace(entry)
+		switch {
+		case entry == "":
+			continue
+		case entry == "direct" || entry == "off":
+		case strings.HasPrefix(entry, "https://"), strings.HasPrefix(entry, "http://"), strings.HasPrefix(entry, "file://"):
+			entry = strings.TrimSuffix(entry, "/")
+		default:
+			// Wie beim go-Befehl ist ein Proxy ohne Schema eine https-Adresse
+			if strings.Contains(entry, "://") {
+				return nil, fmt.Errorf("unsupported GOPROXY entry %q", entry)
+			}
+			entry = "https://" + strings.TrimSuffix(entry, "/")
+		}
+		proxies = append(proxies, moduleProxy{url: entry, fallbackOnError: fallbackOnError})
+	}
+	return proxies, nil
+}
+
+// FetchRepository lädt das Modul entry.Module in der Version entry.Ref. Ohne Ref wird die neueste
+// Version geladen; eine Ref, die keine Version ist (z.B. ein Branch), wird vom Proxy aufgelöst.
+// Als Commit wird die aufgelöste Version zurückgegeben.
+func (p *ModuleProxyProvider) FetchRepository(entry RepositoryEntry) (SourceTree, string, error) {
+	ctx := context.Background()
+	escapedPath, err := EscapeModulePath(entry.Module)
+	if err != nil {
+		return nil, "", err
+	}
+
+	version, err := p.resolveVersion(ctx, escapedPath, entry.Ref)
+	if err != nil {
+		// Offline oder ohne erreichbaren Proxy: zuletzt zu dieser Ref geladene Version aus dem Archiv-Cache
+		cached, ok := "", false
+		if p.Cache != nil {
+			cached, ok = p.Cache.Resolve("modules", escapedPath, entry.Ref)
+		}
+		if !ok {
+			return nil, "", err
+		}
+		version = cached
+	}
+	root := entry.Module + "@" + version + "/"
+
+	if p.Cache != nil {
+		if name, ok := p.Cache.Path("modules", escapedPath, version); ok {
+			tree, err := openArchive(name, root, false)
+			return tree, version, err
+		}
+	}
+
+	name, temporary, err := p.fetchZip(ctx, escapedPath+"/@v/"+escapeModuleVersion(version)+".zip")
+	if err != nil {
+		return nil, "", fmt.Errorf("konnte Modul nicht laden: %w", err)
+	}
+	if !temporary {
+		// Archive aus file://-Proxys und dem Modul-Cache liegen bereits dauerhaft auf der Festplatte
+		tree, err := openArchive(name, root, false)
+		return tree, version, err
+	}
+	tree, err := openDownloadedArchive(p.Cache, "modules", escapedPath, entry.Ref, version, name, root)
+	return tree, version, err
+}
+
+// resolveVersion löst ref auf eine Version auf:
+//   - leere Ref: /@latest bzw. die höchste Version aus /@v/list (Releases vor Pre-Releases)
+//   - Version (v1.2.3, Pseudo-Version): unverändert
+//   - sonst (Branch, Commit): Abfrage über /@v/<ref>.info, die nur Proxys wie proxy.golang.org beantworten
+func (p *ModuleProxyProvider) resolveVersion(ctx context.Context, escapedPath, ref string) (string, error) {
+	if _, isDate := parseRefDate(ref); isDate {
+		return "", fmt.Errorf("module versions cannot be selected by date (%s)", ref)
+	}
+	if isSemver(ref) {
+		return ref, nil
+	}
+
+	var info struct {
+		Version string
+	}
+	if ref != "" {
+		data, err := p.fetch(ctx, escapedPath+"/@v/"+escapeModuleVersion(ref)+".info")
+		if err != nil {
+			return "", fmt.Errorf("konnte Version nicht auflösen: %w", err)
+		}
+		if err := json.Unmarshal(data, &info); err != nil || info.Version == "" {
+			return "", fmt.Errorf("invalid version info of %s@%s", escapedPath, ref)
+		}
+		return info.Version, nil
+	}
+
+	// file://-Proxys und der Modul-Cache haben kein /@latest, aber eine Versionsliste
+	if data, err := p.fetch(ctx, escapedPath+"/@v/list"); err == nil {
+		if version := latestVersion(strings.Fields(string(data))); version != "" {
+			return version, nil
+		}
+	}
+	data, err := p.fetch(ctx, escapedPath+"/@latest")
+	if err != nil {
+		return "", fmt.Errorf("konnte neueste Version nicht ermitteln: %w", err)
+	}
+	if err := json.Unmarshal(data, &info); err != nil || info.Version == "" {
+		return "", fmt.Errorf("invalid version info of %s@latest", escapedPath)
+	}
+	return info.Version, nil
+}
+
+// fetch lädt eine kleine Datei des Proxy-Protokolls wie "golang.org/x/exp/@v/list" in den Speicher
+func (p *ModuleProxyProvider) fetch(ctx context.Context, file string) ([]byte, error) {
+	var data []byte
+	err := p.eachProxy(file, func(location string, isFile bool) error {
+		var err error
+		if isFile {
+			data, err = os.ReadFile(location)
+		} else {
+			data, err = p.get(ctx, location)
+		}
+		return err
+	})
+	return data, err
+}
+
+// fetchZip liefert den Pfad des Archivs einer Version. Aus file://-Proxys und dem Modul-Cache wird es direkt
+// verwendet, von anderen Proxys in eine temporäre Datei geladen, die der Aufrufer löschen muss (temporary).
+func (p *ModuleProxyProvider) fetchZip(ctx context.Context, file string) (name string, temporary bool, err error) {
+	err = p.eachProxy(file, func(location string, isFile bool) error {
+		if isFile {
+			if _, err := os.Stat(location); err != nil {
+				return err
+			}
+			name, temporary = location, false
+			return nil
+		}
+		var err error
+		name, err = p.getFile(ctx, location)
+		temporary = err == nil
+		return err
+	})
+	return name, temporary, err
+}
+
+// eachProxy fragt die Proxys für eine Datei des Proxy-Protokolls der Reihe nach wie der go-Befehl, bis try
+// erfolgreich ist. location ist bei file://-Proxys ein Pfad im Dateisystem (isFile), sonst eine URL.
+func (p *ModuleProxyProvider) eachProxy(file string, try func(location string, isFile bool) error) error {
+	immutable := strings.HasSuffix(file, ".zip") || (strings.HasSuffix(file, ".info") && isSemver(strings.TrimSuffix(path.Base(file), ".info")))
+
+	err := fmt.Errorf("no module proxy configured")
+	for _, proxy := range p.proxies {
+		switch {
+		case proxy.modCache && !immutable && !p.Offline:
+			continue
+		case proxy.url == "off":
+			return fmt.Errorf("%s: module lookup disabled by GOPROXY=off", file)
+		case proxy.url == "direct":
+			return fmt.Errorf("%s: not found in GOPROXY and direct version control access is not supported: %w", file, err)
+		}
+
+		if filePath, isFile := strings.CutPrefix(proxy.url, "file://"); isFile {
+			err = try(filepath.FromSlash(filePath+"/"+file), true)
+		} else if p.Offline {
+			err = fmt.Errorf("%s: %s is not available offline: %w", file, proxy.url, fs.ErrNotExist)
+		} else {
+			err = try(proxy.url+"/"+file, false)
+		}
+		if err == nil {
+			return nil
+		}
+		if !proxy.fallbackOnError && !isNotFound(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// isNotFound erkennt, ob ein Proxy das Modul oder die Version nicht kennt
+func isNotFound(err error) bool {
+	var status *statusError
+	if errors.As(err, &status) {
+		return status.code == http.StatusNotFound || status.code == http.StatusGone
+	}
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+// latestVersion liefert die höchste Version einer Versionsliste; Pre-Releases nur, wenn es kein Release gibt
+func latestVersion(versions []string) string {
+	latestRelease, latestPrerelease := "", ""
+	for _, version := range versions {
+		parsed, ok := parseSemver(version)
+		switch {
+		case !ok:
+		case parsed.prerelease == "" && (latestRelease == "" || compareSemver(version, latestRelease) > 0):
+			latestRelease = version
+		case parsed.prerelease != "" && (latestPrerelease == "" || compareSemver(version, latestPrerelease) > 0):
+			latestPrerelease = version
+		}
+	}
+	if latestRelease != "" {
+		return latestRelease
+	}
+	return latestPrerelease
+}
+
+// semver ist eine zerlegte Version der Form vMAJOR[.MINOR[.PATCH]][-PRERELEASE][+BUILD]
+type semver struct {
+	numbers    [3]int
+	prerelease string
+}
+
+func parseSemver(version string) (semver, bool) {
+	rest, found := strings.CutPrefix(version, "v")
+	if !found {
+		return semver{}, false
+	}
+	rest, _, _ = strings.Cut(rest, "+")
+	var parsed semver
+	rest, parsed.prerelease, _ = strings.Cut(rest, "-")
+
+	parts := strings.Split(rest, ".")
+	if len(parts) > 3 {
+		return semver{}, false
+	}
+	for i, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 || (len(part) > 1 && part[0] == '0') {
+			return semver{}, false
+		}
+		parsed.numbers[i] = number
+	}
+	return parsed, true
+}
+
+func isSemver(version string) bool {
+	_, ok := parseSemver(version)
+	return ok
+}
+
+// compareSemver vergleicht zwei gültige Versionen nach den Regeln von Semantic Versioning:
+// Ein Release ist größer als seine Pre-Releases, Pre-Releases werden Teil für Teil verglichen
+func compareSemver(a, b string) int {
+	va, _ := parseSemver(a)
+	vb, _ := parseSemver(b)
+	for i := range va.numbers {
+		if va.numbers[i] != vb.numbers[i] {
+			return cmp.Compare(va.numbers[i], vb.numbers[i])
+		}
+	}
+	switch {
+	case va.prerelease == vb.prerelease:
+		return 0
+	case va.prerelease == "":
+		return 1
+	case vb.prerelease == "":
+		return -1
+	}
+
+	pa, pb := strings.Split(va.prerelease, "."), strings.Split(vb.prerelease, ".")
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		if pa[i] == pb[i] {
+			continue
+		}
+		na, errA := strconv.Atoi(pa[i])
+		nb, errB := strconv.Atoi(pb[i])
+		switch {
+		case errA == nil && errB == nil:
+			return cmp.Compare(na, nb)
+		case errA == nil:
+			return -1 // numerische Teile sind kleiner als alphanumerische
+		case errB == nil:
+			return 1
+		}
+		return strings.Compare(pa[i], pb[i])
+	}
+	return cmp.Compare(len(pa), len(pb))
+}
@@ -6,15 +6,33 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 )
 
 type SetupConfiguration struct {
-	Token        string
-	CSVPath      string
-	LocalProject string
+	Token           string
+	GitLabToken     string
+	GiteaToken      string
+	GOPROXY         string // Proxys des Modul-Providers, leer = Voreinstellung des go-Befehls
+	ModCache        string // lokaler Modul-Cache, leer, wenn keiner existiert
+	CSVPath         string
+	LocalProject    string
+	AnalysisMode    string
+	ArchiveCacheDir string // leer, wenn der Cache deaktiviert ist
+	RequestTimeout  time.Duration
 }
 
-func SetupEnvironment() (SetupConfiguration, error) {
+const (
+	// AnalysisModeSyntactic analysiert ausschließlich den AST (Standard)
+	AnalysisModeSyntactic = "syntactic"
+	// AnalysisModeTypes führt zusätzlich eine Typprüfung mit go/types durch
+	AnalysisModeTypes = "types"
+)
+
+// SetupEnvironment liest die Konfiguration aus der Secret-Datei und den Environment-Variablen.
+// Die Tokens sind hier optional: Ob der GitHub Token benötigt wird, hängt von den Repositories
+// der Input-Datei ab (siehe RequiresGitHubToken).
+func SetupEnvironment(offline bool) (SetupConfiguration, error) {
 	config := SetupConfiguration{}
 
 	secretsPath := os.Getenv("GOPARSER_SECRETS_PATH")
@@ -34,11 +52,7 @@ func SetupEnvironment() (SetupConfiguration, error) {
 	// Prüfe zuerst ob lokaler Modus aktiviert ist
 	config.LocalProject = os.Getenv("LOCAL_PROJECT_PATH")
 
-	// Im lokalen Modus ist der GitHub Token optional
 	token := os.Getenv("GITHUB_TOKEN")
-	if token == "" && config.LocalProject == "" {
-		return SetupConfiguration{}, fmt.Errorf("GITHUB_TOKEN not found - set GOPARSER_SECRETS_PATH or use VSCode launch.json")
-	}
 
 	csvPath := os.Getenv("CSV_PATH")
 	if csvPath == "" {
@@ -50,7 +64,50 @@ func SetupEnvironment() (SetupConfiguration, error) {
 		csvPath = fmt.Sprintf("%s/input/alleSourcegraph.csv", dir)
 	}
 
+	analysisMode := os.Getenv("ANALYSIS_MODE")
+	switch analysisMode {
+	case "":
+		analysisMode = AnalysisModeSyntactic
+	case AnalysisModeSyntactic, AnalysisModeTypes:
+	default:
+		return SetupConfiguration{}, fmt.Errorf("unknown ANALYSIS_MODE %q - use %q or %q", analysisMode, AnalysisModeSyntactic, AnalysisModeTypes)
+	}
+
+	// Archiv-Cache: nur wenn ARCHIVE_CACHE_DIR gesetzt ist, da er bei großen Crawls viel Platz belegt.
+	// "default" wählt das Standardverzeichnis des Benutzers, "off" (wie leer) deaktiviert ihn.
+	archiveCacheDir := os.Getenv("ARCHIVE_CACHE_DIR")
+	switch archiveCacheDir {
+	case "default":
+		dir, err := DefaultArchiveCacheDir()
+		if err != nil {
+			return SetupConfiguration{}, fmt.Errorf("failed to determine archive cache directory: %w", err)
+		}
+		archiveCacheDir = dir
+	case "off":
+		archiveCacheDir = ""
+	}
+	if archiveCacheDir == "" && offline {
+		return SetupConfiguration{}, fmt.Errorf("offline mode requires the archive cache - set ARCHIVE_CACHE_DIR")
+	}
+
+	// Zeitlimit je Versuch einer Anfrage, z.B. "30m" für sehr große Archive über eine langsame Verbindung
+	requestTimeout := DefaultRequestTimeout
+	if value := os.Getenv("REQUEST_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return SetupConfiguration{}, fmt.Errorf("invalid REQUEST_TIMEOUT %q - use a duration like \"10m\"", value)
+		}
+		requestTimeout = timeout
+	}
+
+	config.ArchiveCacheDir = archiveCacheDir
+	config.RequestTimeout = requestTimeout
+	config.AnalysisMode = analysisMode
 	config.Token = token
+	config.GitLabToken = os.Getenv("GITLAB_TOKEN")
+	config.GiteaToken = os.Getenv("GITEA_TOKEN")
+	config.GOPROXY = os.Getenv("GOPROXY")
+	config.ModCache = DefaultModCache()
 	config.CSVPath = csvPath
 	return config, nil
 }
@@ -88,3 +145,18 @@ func loadEnvFile(filepath string) error {
 
 	return scanner.Err()
 }
+
+// RequiresGitHubToken prüft, ob für die Repositories ein GitHub Token benötigt wird. Ohne Token sind nur
+// 60 Anfragen pro Stunde möglich, daher ist er Pflicht, sobald ein Repository von GitHub geladen wird.
+// Im Offline-Modus werden nur Archive aus dem Cache analysiert.
+func RequiresGitHubToken(repositories []RepositoryEntry, offline bool) bool {
+	if offline {
+		return false
+	}
+	for _, repository := range repositories {
+		if repository.Provider == ProviderGitHub {
+			return true
+		}
+	}
+	return false
+}
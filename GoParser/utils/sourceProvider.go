@@ -0,0 +1,353 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultRequestTimeout begrenzt einen einzelnen Versuch einer Anfrage, wenn REQUEST_TIMEOUT nicht gesetzt ist.
+// Das reicht auch für Archive großer Repositories, bricht aber eine hängende Übertragung ab.
+const DefaultRequestTimeout = 10 * time.Minute
+
+// SourceProvider lädt den Quellcode eines Repositories von einem Hoster
+type SourceProvider interface {
+	// FetchRepository lädt das Archiv des Repositories an entry.Ref auf die Festplatte und liefert
+	// seine .go-, go.mod- und go.work-Dateien als SourceTree zusammen mit dem SHA des analysierten Commits.
+	// Der Aufrufer muss den Baum schließen.
+	FetchRepository(entry RepositoryEntry) (SourceTree, string, error)
+}
+
+// ProviderConfig enthält die Einstellungen, die für alle Provider gelten
+type ProviderConfig struct {
+	GitHubToken string
+	GitLabToken string
+	GiteaToken  string
+	// GOPROXY und ModCache werden vom Modul-Provider verwendet (siehe NewModuleProxyProvider)
+	GOPROXY  string
+	ModCache string
+	// Cache ist optional und wird von allen Providern gemeinsam genutzt
+	Cache *ArchiveCache
+	// Offline analysiert ausschließlich Archive aus dem Cache
+	Offline bool
+	// Timeout begrenzt jeden Versuch einer Anfrage, 0 = DefaultRequestTimeout
+	Timeout time.Duration
+}
+
+// SourceProviders wählt für jedes Repository den Provider aus, der zu seinem Host passt.
+// Pro Host gibt es genau einen Provider, damit dieser das Rate Limit aller Worker gemeinsam beachtet.
+type SourceProviders struct {
+	config ProviderConfig
+
+	mu        sync.Mutex
+	providers map[string]SourceProvider // Provider/Host -> Provider
+}
+
+func NewSourceProviders(config ProviderConfig) *SourceProviders {
+	return &SourceProviders{config: config, providers: make(map[string]SourceProvider)}
+}
+
+// FetchRepository lädt ein Repository über den Provider seines Hosts
+func (p *SourceProviders) FetchRepository(entry RepositoryEntry) (SourceTree, string, error) {
+	provider, err := p.provider(entry)
+	if err != nil {
+		return nil, "", err
+	}
+	return provider.FetchRepository(entry)
+}
+
+func (p *SourceProviders) provider(entry RepositoryEntry) (SourceProvider, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := entry.Provider + "/" + entry.Host
+	if provider, ok := p.providers[key]; ok {
+		return provider, nil
+	}
+
+	timeout := p.config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+
+	var provider SourceProvider
+	switch entry.Provider {
+	case ProviderGitHub, "":
+		client := newGitHubClient(p.config.GitHubToken, timeout)
+		if entry.Host != "" && entry.Host != "github.com" {
+			// GitHub Enterprise Server
+			if err := client.SetBaseURL("https://" + entry.Host + "/api/v3/"); err != nil {
+				return nil, err
+			}
+			client.Host = entry.Host
+		}
+		client.Cache, client.Offline = p.config.Cache, p.config.Offline
+		provider = client
+	case ProviderGitLab:
+		gitlab := NewGitLabProvider(p.config.GitLabToken)
+		gitlab.Cache, gitlab.Offline = p.config.Cache, p.config.Offline
+		gitlab.setTimeout(timeout)
+		provider = gitlab
+	case ProviderGitea:
+		gitea := NewGiteaProvider(p.config.GiteaToken)
+		gitea.Cache, gitea.Offline = p.config.Cache, p.config.Offline
+		gitea.setTimeout(timeout)
+		provider = gitea
+	case ProviderArchive:
+		archive := NewArchiveURLProvider()
+		archive.Cache, archive.Offline = p.config.Cache, p.config.Offline
+		archive.setTimeout(timeout)
+		provider = archive
+	case ProviderModule:
+		modules, err := NewModuleProxyProvider(p.config.GOPROXY, p.config.ModCache)
+		if err != nil {
+			return nil, err
+		}
+		modules.Cache, modules.Offline = p.config.Cache, p.config.Offline
+		modules.setTimeout(timeout)
+		provider = modules
+	default:
+		return nil, fmt.Errorf("unknown provider %q", entry.Provider)
+	}
+	p.providers[key] = provider
+	return provider, nil
+}
+
+// fetchArchive ist der gemeinsame Ablauf aller Provider: ref mit resolve auf einen Commit auflösen,
+// das Archiv des Commits aus dem Cache nehmen oder mit download in eine temporäre Datei laden und als
+// SourceTree öffnen. Offline wird ausschließlich der Cache verwendet. owner/repo bestimmen den Pfad im Cache.
+func fetchArchive(cache *ArchiveCache, offline bool, owner, repo, ref string, resolve func() (string, error), download func(sha string) (string, error)) (SourceTree, string, error) {
+	if offline {
+		return fetchFromCache(cache, owner, repo, ref)
+	}
+
+	// Ref auf einen Commit-SHA auflösen, damit das Ergebnis reproduzierbar ist
+	sha, err := resolve()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if cache != nil {
+		if name, ok := cache.Path(owner, repo, sha); ok {
+			tree, err := openArchive(name, "", false)
+			return tree, sha, err
+		}
+	}
+
+	tmp, err := download(sha)
+	if err != nil {
+		return nil, "", fmt.Errorf("konnte Archiv nicht laden: %w", err)
+	}
+	tree, err := openDownloadedArchive(cache, owner, repo, ref, sha, tmp, "")
+	return tree, sha, err
+}
+
+// openDownloadedArchive legt ein heruntergeladenes Archiv im Cache ab und öffnet es dort.
+// Ohne Cache oder wenn das Ablegen fehlschlägt, wird die temporäre Datei geöffnet und beim Schließen gelöscht.
+func openDownloadedArchive(cache *ArchiveCache, owner, repo, ref, sha, tmp, root string) (SourceTree, error) {
+	if cache != nil {
+		name, err := cache.Put(owner, repo, ref, sha, tmp)
+		if err == nil {
+			return openArchive(name, root, false)
+		}
+		log.Printf("Could not cache archive of %s/%s: %v", owner, repo, err)
+		if name != "" {
+			// Das Archiv liegt bereits im Cache, nur die Ref konnte nicht gespeichert werden
+			return openArchive(name, root, false)
+		}
+	}
+	return openArchive(tmp, root, true)
+}
+
+// fetchFromCache öffnet das Archiv, zu dem ref beim letzten Download aufgelöst wurde
+func fetchFromCache(cache *ArchiveCache, owner, repo, ref string) (SourceTree, string, error) {
+	if cache == nil {
+		return nil, "", fmt.Errorf("offline mode requires an archive cache")
+	}
+	sha, ok := cache.Resolve(owner, repo, ref)
+	if !ok {
+		// Ein vollständiger SHA kann auch ohne vorherige Auflösung direkt im Cache liegen
+		sha = ref
+	}
+	name, ok := cache.Path(owner, repo, sha)
+	if !ok {
+		if ref == "" {
+			return nil, "", fmt.Errorf("%s/%s is not in the archive cache", owner, repo)
+		}
+		return nil, "", fmt.Errorf("%s/%s@%s is not in the archive cache", owner, repo, ref)
+	}
+	tree, err := openArchive(name, "", false)
+	return tree, sha, err
+}
+
+// httpSource führt die HTTP-Anfragen der Provider ohne eigenen API-Client aus. Wie beim GitHubClient
+// werden vorübergehende Fehler (5xx, 429, Netzwerkfehler) mit exponentiellem Backoff wiederholt.
+type httpSource struct {
+	client *http.Client
+	header http.Header // z.B. der Token, wird jeder Anfrage hinzugefügt
+	name   string      // für Log-Ausgaben, z.B. "GitLab"
+
+	// MaxAttempts ist die maximale Anzahl Versuche pro Anfrage
+	MaxAttempts int
+	// Backoff ist die Wartezeit vor der ersten Wiederholung; sie verdoppelt sich mit jedem Versuch
+	Backoff time.Duration
+	// MaxBackoff begrenzt die Wartezeit zwischen zwei Versuchen
+	MaxBackoff time.Duration
+	// Timeout begrenzt jeden Versuch einschließlich der Übertragung der Antwort; 0 = ohne Begrenzung
+	Timeout time.Duration
+}
+
+func newHTTPSource(name string) httpSource {
+	return httpSource{
+		client:      &http.Client{Timeout: DefaultRequestTimeout},
+		header:      make(http.Header),
+		name:        name,
+		MaxAttempts: 5,
+		Backoff:     2 * time.Second,
+		MaxBackoff:  2 * time.Minute,
+		Timeout:     DefaultRequestTimeout,
+	}
+}
+
+// setTimeout setzt das Zeitlimit je Versuch und das des http.Client
+func (s *httpSource) setTimeout(timeout time.Duration) {
+	s.Timeout = timeout
+	s.client.Timeout = timeout
+}
+
+// withTimeout liefert den Kontext für einen einzelnen Versuch; ohne timeout läuft er nicht ab
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// statusError ist eine Antwort mit einem Fehlerstatus
+type statusError struct {
+	url    string
+	status string
+	code   int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("GET %s: %s", e.url, e.status)
+}
+
+// get lädt rawURL in den Speicher; für kleine Antworten wie JSON gedacht, Archive lädt getFile.
+// Schlägt die Anfrage endgültig fehl, ist der Fehler ein *FetchError mit der Anzahl der Versuche.
+func (s *httpSource) get(ctx context.Context, rawURL string) ([]byte, error) {
+	var data []byte
+	err := s.do(ctx, rawURL, func(body io.Reader) error {
+		var err error
+		data, err = io.ReadAll(body)
+		return err
+	})
+	return data, err
+}
+
+// getFile lädt rawURL in eine temporäre Datei und liefert ihren Namen; der Aufrufer muss sie löschen
+func (s *httpSource) getFile(ctx context.Context, rawURL string) (string, error) {
+	var name string
+	err := s.do(ctx, rawURL, func(body io.Reader) error {
+		var err error
+		name, err = writeTempArchive(body)
+		return err
+	})
+	return name, err
+}
+
+// do führt eine Anfrage aus und übergibt die Antwort an read. Jeder Versuch ruft read erneut auf und
+// wird nach s.Timeout abgebrochen, auch wenn die Übertragung der Antwort nur hängt.
+func (s *httpSource) do(ctx context.Context, rawURL string, read func(body io.Reader) error) error {
+	maxAttempts := max(s.MaxAttempts, 1)
+
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		attemptCtx, cancel := withTimeout(ctx, s.Timeout)
+		var resp *http.Response
+		resp, err = s.request(attemptCtx, rawURL, read)
+		cancel()
+		if err == nil {
+			return nil
+		}
+
+		wait, retry := s.retryDelay(resp, attempt)
+		if !retry || attempt == maxAttempts {
+			return &FetchError{Attempts: attempt, Err: err}
+		}
+
+		log.Printf("%s request failed (attempt %d/%d), retrying in %s: %v", s.name, attempt, maxAttempts, wait.Round(time.Millisecond), err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return &FetchError{Attempts: attempt, Err: ctx.Err()}
+		}
+	}
+	return &FetchError{Attempts: maxAttempts, Err: err}
+}
+
+// getJSON lädt rawURL und dekodiert die Antwort in v
+func (s *httpSource) getJSON(ctx context.Context, rawURL string, v any) error {
+	data, err := s.get(ctx, rawURL)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("GET %s: %w", rawURL, err)
+	}
+	return nil
+}
+
+func (s *httpSource) request(ctx context.Context, rawURL string, read func(body io.Reader) error) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range s.header {
+		req.Header[key] = values
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, &statusError{url: rawURL, status: resp.Status, code: resp.StatusCode}
+	}
+	if err := read(resp.Body); err != nil {
+		// Abgebrochene Übertragung wie ein Netzwerkfehler behandeln
+		return nil, err
+	}
+	return resp, nil
+}
+
+// retryDelay entscheidet, ob eine Anfrage wiederholt wird, und wie lange vorher gewartet wird
+func (s *httpSource) retryDelay(resp *http.Response, attempt int) (time.Duration, bool) {
+	backoff := s.Backoff << (attempt - 1)
+	if s.MaxBackoff > 0 && backoff > s.MaxBackoff {
+		backoff = s.MaxBackoff
+	}
+
+	if resp == nil {
+		// Keine Antwort erhalten (Netzwerkfehler, Timeout)
+		return backoff, true
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusForbidden:
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return retryAfter, true
+		}
+		return backoff, resp.StatusCode == http.StatusTooManyRequests
+	case resp.StatusCode >= 500:
+		return backoff, true
+	}
+	return 0, false
+}
@@ -0,0 +1,435 @@
+package utils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRepositorySelectsProvider(t *testing.T) {
+	for _, test := range []struct {
+		repository, provider string
+		want                 RepositoryEntry
+		name                 string
+	}{
+		{"github.com/owner/repo", "", RepositoryEntry{Provider: ProviderGitHub, Host: "github.com", Owner: "owner", Repo: "repo"}, "owner/repo"},
+		{"https://github.com/owner/repo.git", "", RepositoryEntry{Provider: ProviderGitHub, Host: "github.com", Owner: "owner", Repo: "repo"}, "owner/repo"},
+		{"gitlab.com/group/sub/project", "", RepositoryEntry{Provider: ProviderGitLab, Host: "gitlab.com", Owner: "group/sub", Repo: "project"}, "gitlab.com/group/sub/project"},
+		{"git.example.com/team/project", "gitlab", RepositoryEntry{Provider: ProviderGitLab, Host: "git.example.com", Owner: "team", Repo: "project"}, "git.example.com/team/project"},
+		{"codeberg.org/owner/repo", "", RepositoryEntry{Provider: ProviderGitea, Host: "codeberg.org", Owner: "owner", Repo: "repo"}, "codeberg.org/owner/repo"},
+		{"forge.example.org/owner/repo", "Forgejo", RepositoryEntry{Provider: ProviderGitea, Host: "forge.example.org", Owner: "owner", Repo: "repo"}, "forge.example.org/owner/repo"},
+		{"https://example.com/release.tar.gz", "", RepositoryEntry{Provider: ProviderArchive, URL: "https://example.com/release.tar.gz"}, "https://example.com/release.tar.gz"},
+		{"golang.org/x/exp@v0.0.0-20240506185415-9bf2ced13842", "", RepositoryEntry{Provider: ProviderModule, Module: "golang.org/x/exp", Ref: "v0.0.0-20240506185415-9bf2ced13842"}, "golang.org/x/exp"},
+		{"golang.org/x/exp", "module", RepositoryEntry{Provider: ProviderModule, Module: "golang.org/x/exp"}, "golang.org/x/exp"},
+	} {
+		got, err := ParseRepository(test.repository, test.provider)
+		if err != nil {
+			t.Errorf("ParseRepository(%q, %q) failed: %v", test.repository, test.provider, err)
+			continue
+		}
+		if got != test.want || got.Name() != test.name {
+			t.Errorf("ParseRepository(%q, %q) = %+v named %s, want %+v named %s", test.repository, test.provider, got, got.Name(), test.want, test.name)
+		}
+	}
+
+	for _, repository := range []string{"git.example.com/team/project", "github.com/owner", "github.com/owner/repo/tree/main", "example.com/source.zip", "golang.org/x/exp@"} {
+		if entry, err := ParseRepository(repository, ""); err == nil {
+			t.Errorf("ParseRepository(%q) = %+v, want an error", repository, entry)
+		}
+	}
+}
+
+func TestGetOwnerAndRepoReadsProviderColumn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.csv")
+	content := "Match type,Repository,Ref,Provider\n" +
+		"repo,github.com/owner/repo,v1.0,\n" +
+		"repo,git.example.com/team/project,,gitlab\n" +
+		"repo,git.example.com/team/unknown,,\n" +
+		"repo,gitlab.com/group/project\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := GetOwnerAndRepo(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Provider+":"+entry.Name()+"@"+entry.Ref)
+	}
+	want := "github:owner/repo@v1.0 gitlab:git.example.com/team/project@ gitlab:gitlab.com/group/project@"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("got entries %s, want %s", got, want)
+	}
+}
+
+// testArchive erzeugt ein ZIP-Archiv mit den Dateien eines Repositories unter root
+func testArchive(t *testing.T, root string) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	zw := zip.NewWriter(&buffer)
+	for name, content := range map[string]string{
+		"go.mod":      "module example.com/repo\n",
+		"main.go":     "package main\n",
+		"README.md":   "# repo\n",
+		"pkg/list.go": "package pkg\n",
+	} {
+		w, err := zw.Create(root + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprint(w, content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func TestGitLabProviderFetchesProject(t *testing.T) {
+	archive := testArchive(t, "project-abc123-abc123/")
+	var commitListQuery url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/group%2Fsub%2Fproject":
+			fmt.Fprint(w, `{"default_branch": "main"}`)
+		case "/api/v4/projects/group%2Fsub%2Fproject/repository/commits/main":
+			fmt.Fprint(w, `{"id": "abc123"}`)
+		case "/api/v4/projects/group%2Fsub%2Fproject/repository/commits/release%2Fv1":
+			fmt.Fprint(w, `{"id": "def456"}`)
+		case "/api/v4/projects/group%2Fsub%2Fproject/repository/commits":
+			commitListQuery = r.URL.Query()
+			fmt.Fprint(w, `[{"id": "789abc"}]`)
+		case "/api/v4/projects/group%2Fsub%2Fproject/repository/archive.zip":
+			w.Write(archive)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	provider := NewGitLabProvider("secret")
+	provider.Scheme = "http"
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	for _, test := range []struct {
+		ref  string
+		want string
+	}{
+		{"", "abc123"},
+		{"release/v1", "def456"},
+		{"2024-01-01", "789abc"},
+	} {
+		files, sha, err := readTree(provider.FetchRepository(RepositoryEntry{Provider: ProviderGitLab, Host: host, Owner: "group/sub", Repo: "project", Ref: test.ref}))
+		if err != nil {
+			t.Fatalf("fetch of ref %q failed: %v", test.ref, err)
+		}
+		if sha != test.want || len(files) != 3 {
+			t.Errorf("ref %q resolved to %s with %d files, want %s with 3 files", test.ref, sha, len(files), test.want)
+		}
+	}
+	if got := commitListQuery.Get("until"); got != "2024-01-01T00:00:00Z" {
+		t.Errorf("commits were listed until %q, want 2024-01-01T00:00:00Z", got)
+	}
+	if got := commitListQuery.Get("ref_name"); got != "main" {
+		t.Errorf("commits were listed on %q, want the default branch", got)
+	}
+}
+
+func TestGiteaProviderFetchesRepository(t *testing.T) {
+	archive := testArchive(t, "repo/")
+	failures := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/repos/owner/repo":
+			if failures > 0 {
+				failures--
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			fmt.Fprint(w, `{"default_branch": "main"}`)
+		case "/api/v1/repos/owner/repo/commits":
+			if r.URL.Query().Get("sha") != "main" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fmt.Fprint(w, `[{"sha": "abc123"}]`)
+		case "/api/v1/repos/owner/repo/archive/abc123.zip":
+			w.Write(archive)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	provider := NewGiteaProvider("")
+	provider.Scheme = "http"
+	provider.Backoff = time.Millisecond
+	provider.Cache = NewArchiveCache(t.TempDir())
+	entry := RepositoryEntry{Provider: ProviderGitea, Host: strings.TrimPrefix(server.URL, "http://"), Owner: "owner", Repo: "repo"}
+
+	files, sha, err := readTree(provider.FetchRepository(entry))
+	if err != nil {
+		t.Fatalf("expected success after retry, got %v", err)
+	}
+	if sha != "abc123" || len(files) != 3 {
+		t.Errorf("got %s with %d files, want abc123 with 3 files", sha, len(files))
+	}
+	for _, f := range files {
+		if strings.HasPrefix(f.Path, "repo/") {
+			t.Errorf("archive root was not stripped from %s", f.Path)
+		}
+	}
+
+	// Offline wird das Archiv aus dem Cache verwendet
+	server.Close()
+	provider.Offline = true
+	if _, sha, err := readTree(provider.FetchRepository(entry)); err != nil || sha != "abc123" {
+		t.Errorf("offline fetch returned %s and error %v, want abc123", sha, err)
+	}
+
+	// Ein nicht erreichbarer Host liefert nach den Wiederholungen einen Fehler
+	provider.Offline = false
+	if _, _, err := readTree(provider.FetchRepository(RepositoryEntry{Provider: ProviderGitea, Host: "127.0.0.1:0", Owner: "owner", Repo: "repo", Ref: "v1"})); err == nil {
+		t.Errorf("expected error for unreachable host")
+	}
+}
+
+func TestHTTPSourceAbortsStalledTransfer(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte("PK"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+
+	provider := NewArchiveURLProvider()
+	provider.setTimeout(100 * time.Millisecond)
+	provider.MaxAttempts, provider.Backoff = 2, time.Millisecond
+
+	_, _, err := provider.FetchRepository(RepositoryEntry{Provider: ProviderArchive, URL: server.URL + "/release.zip"})
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) || fetchErr.Attempts != 2 {
+		t.Fatalf("got %v, want a FetchError after 2 attempts", err)
+	}
+	if requests != 2 {
+		t.Errorf("got %d requests, want 2", requests)
+	}
+}
+
+func TestArchiveURLProviderReadsTarGz(t *testing.T) {
+	// Archiv ohne gemeinsames oberstes Verzeichnis: kein Pfad darf gekürzt werden
+	var buffer bytes.Buffer
+	gz := gzip.NewWriter(&buffer)
+	tw := tar.NewWriter(gz)
+	for name, content := range map[string]string{
+		"./go.mod":    "module example.com/release\n",
+		"main.go":     "package main\n",
+		"pkg/list.go": "package pkg\n",
+		"README.md":   "# release\n",
+	} {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprint(tw, content)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(buffer.Bytes())
+	}))
+	t.Cleanup(server.Close)
+
+	provider := NewArchiveURLProvider()
+	files, sha, err := readTree(provider.FetchRepository(RepositoryEntry{Provider: ProviderArchive, URL: server.URL + "/release.tar.gz"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := make(map[string]bool)
+	for _, f := range files {
+		paths[f.Path] = true
+	}
+	if len(files) != 3 || !paths["go.mod"] || !paths["pkg/list.go"] {
+		t.Errorf("got files %v, want go.mod, main.go and pkg/list.go", paths)
+	}
+	if len(sha) != 64 {
+		t.Errorf("got commit %q, want the SHA-256 of the archive", sha)
+	}
+
+	if _, _, err := readTree(provider.FetchRepository(RepositoryEntry{Provider: ProviderArchive, URL: server.URL + "/release.tar.gz", Ref: "v1"})); err == nil {
+		t.Errorf("expected error for archive pinned to a ref")
+	}
+
+	// Im Cache liegt das tar.gz als ZIP mit den Quelldateien, offline wird es von dort gelesen
+	provider.Cache = NewArchiveCache(t.TempDir())
+	if _, _, err := readTree(provider.FetchRepository(RepositoryEntry{Provider: ProviderArchive, URL: server.URL + "/release.tar.gz"})); err != nil {
+		t.Fatal(err)
+	}
+	provider.Offline = true
+	files, cachedSHA, err := readTree(provider.FetchRepository(RepositoryEntry{Provider: ProviderArchive, URL: server.URL + "/release.tar.gz"}))
+	if err != nil || len(files) != 3 || cachedSHA != sha {
+		t.Errorf("offline fetch returned %d files at %s and error %v, want 3 files at %s", len(files), cachedSHA, err, sha)
+	}
+}
+
+// writeModuleProxy legt ein Modul im Layout des GOPROXY-Protokolls unter dir ab, wie es auch der Modul-Cache
+// unter GOMODCACHE/cache/download verwendet. Jede Version enthält go.mod und lib.go.
+func writeModuleProxy(t *testing.T, dir, modulePath string, versions ...string) {
+	t.Helper()
+	escapedPath, err := EscapeModulePath(modulePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versionDir := filepath.Join(dir, filepath.FromSlash(escapedPath), "@v")
+	if err := os.MkdirAll(versionDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(versionDir, "list"), []byte(strings.Join(versions, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, version := range versions {
+		var buffer bytes.Buffer
+		zw := zip.NewWriter(&buffer)
+		for name, content := range map[string]string{
+			"go.mod":      "module " + modulePath + "\n",
+			"lib.go":      "package lib\n",
+			"sub/impl.go": "package sub\n",
+		} {
+			w, err := zw.Create(modulePath + "@" + version + "/" + name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fmt.Fprint(w, content)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(versionDir, version+".zip"), buffer.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestModuleProxyProviderReadsFileProxy(t *testing.T) {
+	dir := t.TempDir()
+	writeModuleProxy(t, dir, "example.com/Upper/lib", "v1.0.0", "v1.1.0", "v1.2.0-rc.1")
+
+	provider, err := NewModuleProxyProvider("file://"+filepath.ToSlash(dir), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		ref  string
+		want string
+	}{
+		{"", "v1.1.0"},
+		{"v1.2.0-rc.1", "v1.2.0-rc.1"},
+	} {
+		files, version, err := readTree(provider.FetchRepository(RepositoryEntry{Provider: ProviderModule, Module: "example.com/Upper/lib", Ref: test.ref}))
+		if err != nil {
+			t.Fatalf("fetch of %q failed: %v", test.ref, err)
+		}
+		paths := make(map[string]bool)
+		for _, f := range files {
+			paths[f.Path] = true
+		}
+		if version != test.want || len(files) != 3 || !paths["sub/impl.go"] {
+			t.Errorf("ref %q resolved to %s with files %v, want %s with go.mod, lib.go and sub/impl.go", test.ref, version, paths, test.want)
+		}
+	}
+	if _, _, err := readTree(provider.FetchRepository(RepositoryEntry{Provider: ProviderModule, Module: "example.com/Upper/lib", Ref: "v9.0.0"})); err == nil {
+		t.Errorf("expected error for unknown version")
+	}
+}
+
+func TestModuleProxyProviderFollowsGOPROXY(t *testing.T) {
+	// Der erste Proxy kennt das Modul nicht, der zweite liefert es
+	var missRequests int
+	miss := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		missRequests++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(miss.Close)
+	dir := t.TempDir()
+	writeModuleProxy(t, dir, "example.com/lib", "v0.1.0")
+	hit := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	t.Cleanup(hit.Close)
+
+	provider, err := NewModuleProxyProvider(miss.URL+","+hit.URL+",direct", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, version, err := readTree(provider.FetchRepository(RepositoryEntry{Provider: ProviderModule, Module: "example.com/lib"})); err != nil || version != "v0.1.0" {
+		t.Fatalf("fetch returned %s and error %v, want v0.1.0", version, err)
+	}
+	if missRequests == 0 {
+		t.Errorf("first proxy was not asked")
+	}
+	// Nach "direct" wird nicht weiter gesucht
+	if _, _, err := readTree(provider.FetchRepository(RepositoryEntry{Provider: ProviderModule, Module: "example.com/unknown"})); err == nil || !strings.Contains(err.Error(), "direct") {
+		t.Errorf("got error %v, want a hint that direct access is not supported", err)
+	}
+
+	off, err := NewModuleProxyProvider("off", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := readTree(off.FetchRepository(RepositoryEntry{Provider: ProviderModule, Module: "example.com/lib"})); err == nil {
+		t.Errorf("expected error with GOPROXY=off")
+	}
+}
+
+func TestModuleProxyProviderUsesModCacheOffline(t *testing.T) {
+	modCache := t.TempDir()
+	writeModuleProxy(t, filepath.Join(modCache, "cache", "download"), "example.com/lib", "v1.0.0")
+
+	provider, err := NewModuleProxyProvider("https://127.0.0.1:0", modCache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider.Offline = true
+	files, version, err := readTree(provider.FetchRepository(RepositoryEntry{Provider: ProviderModule, Module: "example.com/lib"}))
+	if err != nil || version != "v1.0.0" || len(files) != 3 {
+		t.Errorf("offline fetch returned %s with %d files and error %v, want v1.0.0 with 3 files", version, len(files), err)
+	}
+}
+
+func TestLatestVersionPrefersReleases(t *testing.T) {
+	for _, test := range []struct {
+		versions []string
+		want     string
+	}{
+		{[]string{"v1.9.0", "v1.10.0", "v1.10.0-rc.1"}, "v1.10.0"},
+		{[]string{"v2.0.0-beta.2", "v2.0.0-beta.10", "v2.0.0-alpha"}, "v2.0.0-beta.10"},
+		{[]string{"v1.0.0", "v2.0.0+incompatible", "master"}, "v2.0.0+incompatible"},
+		{nil, ""},
+	} {
+		if got := latestVersion(test.versions); got != test.want {
+			t.Errorf("latestVersion(%v) = %s, want %s", test.versions, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,344 @@
+package utils
+
+import (
+	"GoParser/model"
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// SourceTree ist der Quellcode eines Repositories, dessen Dateien erst beim Lesen in den Speicher geladen werden.
+// Archive bleiben dazu auf der Festplatte (im Archiv-Cache oder als temporäre Datei), lokale Projekte im Dateisystem.
+// So hängt der Speicherbedarf der Analyse nicht von der Größe des Repositories ab, sondern nur vom größten Paket.
+type SourceTree interface {
+	// Paths liefert die Pfade aller .go-Dateien sowie go.mod- und go.work-Dateien relativ zur Wurzel,
+	// sortiert wie bei SortSourcePaths
+	Paths() []string
+	// ReadFile liest den Inhalt einer Datei aus Paths
+	ReadFile(name string) (string, error)
+	// Close gibt den Baum frei und löscht temporäre Dateien
+	Close() error
+}
+
+// SourceFiles liefert die Dateien eines Baums einzeln in der Reihenfolge von Paths. Die Dateien eines
+// Verzeichnisses folgen direkt aufeinander, ein Verzeichnis kann also analysiert und verworfen werden,
+// sobald die erste Datei des nächsten Verzeichnisses kommt. Dateien, die nicht gelesen werden können,
+// werden mit ihrem Fehler geliefert.
+func SourceFiles(tree SourceTree) iter.Seq2[model.SourceFile, error] {
+	return func(yield func(model.SourceFile, error) bool) {
+		for _, name := range tree.Paths() {
+			content, err := tree.ReadFile(name)
+			if err != nil {
+				if !yield(model.SourceFile{Path: name}, fmt.Errorf("%s: %w", name, err)) {
+					return
+				}
+				continue
+			}
+			if !yield(model.SourceFile{Path: name, Content: content, Category: FileCategory(name, content)}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// SortSourcePaths sortiert Pfade nach Verzeichnis und darin nach Dateiname. Das Wurzelverzeichnis kommt zuerst
+// und jedes Verzeichnis vor seinen Unterverzeichnissen, eine go.mod ist also bekannt, bevor die Pakete
+// ihres Moduls gelesen werden.
+func SortSourcePaths(paths []string) {
+	slices.SortFunc(paths, func(a, b string) int {
+		if c := strings.Compare(sortDir(a), sortDir(b)); c != 0 {
+			return c
+		}
+		return strings.Compare(path.Base(a), path.Base(b))
+	})
+}
+
+func sortDir(name string) string {
+	dir := path.Dir(name)
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
+
+// isSourceFile gibt an, ob eine Datei für die Analyse benötigt wird
+func isSourceFile(name string) bool {
+	return strings.HasSuffix(name, ".go") || path.Base(name) == "go.mod" || path.Base(name) == "go.work"
+}
+
+// memoryTree hält alle Dateien im Speicher, z.B. für Tests und einzelne Dateien
+type memoryTree struct {
+	paths []string
+	files map[string]model.SourceFile
+}
+
+// NewMemoryTree erzeugt einen Baum aus bereits geladenen Dateien
+func NewMemoryTree(files []model.SourceFile) SourceTree {
+	tree := &memoryTree{files: make(map[string]model.SourceFile, len(files))}
+	for _, f := range files {
+		if _, ok := tree.files[f.Path]; !ok {
+			tree.paths = append(tree.paths, f.Path)
+		}
+		tree.files[f.Path] = f
+	}
+	SortSourcePaths(tree.paths)
+	return tree
+}
+
+func (t *memoryTree) Paths() []string {
+	return t.paths
+}
+
+func (t *memoryTree) ReadFile(name string) (string, error) {
+	f, ok := t.files[name]
+	if !ok {
+		return "", os.ErrNotExist
+	}
+	return f.Content, nil
+}
+
+func (t *memoryTree) Close() error {
+	return nil
+}
+
+// dirTree liest die Dateien aus einem Verzeichnis auf der Festplatte. removeDir wird beim Schließen gelöscht,
+// wenn der Baum aus einem temporär entpackten Archiv stammt.
+type dirTree struct {
+	base      string
+	paths     []string
+	removeDir string
+}
+
+func (t *dirTree) Paths() []string {
+	return t.paths
+}
+
+func (t *dirTree) ReadFile(name string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(t.base, filepath.FromSlash(name)))
+	return string(content), err
+}
+
+func (t *dirTree) Close() error {
+	if t.removeDir == "" {
+		return nil
+	}
+	return os.RemoveAll(t.removeDir)
+}
+
+// zipTree liest die Dateien direkt aus einer ZIP-Datei; im Speicher liegt nur ihr Inhaltsverzeichnis.
+// Eine temporäre ZIP-Datei wird beim Schließen gelöscht.
+type zipTree struct {
+	reader    *zip.ReadCloser
+	name      string
+	paths     []string
+	files     map[string]*zip.File
+	temporary bool
+}
+
+func (t *zipTree) Paths() []string {
+	return t.paths
+}
+
+func (t *zipTree) ReadFile(name string) (string, error) {
+	f, ok := t.files[name]
+	if !ok {
+		return "", os.ErrNotExist
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	content, err := io.ReadAll(rc)
+	return string(content), err
+}
+
+func (t *zipTree) Close() error {
+	err := t.reader.Close()
+	if t.temporary {
+		err = errors.Join(err, os.Remove(t.name))
+	}
+	return err
+}
+
+// openArchive öffnet ein ZIP- oder tar.gz-Archiv auf der Festplatte als SourceTree. root (inklusive "/")
+// wird aus allen Pfaden entfernt, Dateien außerhalb von root werden übersprungen. Ist root leer und liegen
+// alle Dateien in einem gemeinsamen obersten Verzeichnis (z.B. "owner-repo-sha/"), wird dieses entfernt.
+// Ist temporary gesetzt, gehört die Datei dem Baum und wird spätestens beim Schließen gelöscht.
+// tar.gz-Archive erlauben keinen wahlfreien Zugriff und werden dazu in ein temporäres Verzeichnis entpackt.
+func openArchive(name, root string, temporary bool) (SourceTree, error) {
+	tree, err := openArchiveTree(name, root, temporary)
+	if err != nil && temporary {
+		os.Remove(name)
+	}
+	return tree, err
+}
+
+func openArchiveTree(name, root string, temporary bool) (SourceTree, error) {
+	isGzip, err := hasGzipMagic(name)
+	if err != nil {
+		return nil, err
+	}
+	if isGzip {
+		tree, err := extractTarGz(name, root)
+		if err != nil {
+			return nil, fmt.Errorf("konnte tar.gz nicht entpacken: %w", err)
+		}
+		if temporary {
+			os.Remove(name)
+		}
+		return tree, nil
+	}
+
+	reader, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, fmt.Errorf("konnte ZIP nicht entpacken: %w", err)
+	}
+	var names []string
+	for _, f := range reader.File {
+		if !f.FileInfo().IsDir() && isSourceFile(f.Name) {
+			names = append(names, f.Name)
+		}
+	}
+	if root == "" {
+		root = archiveRoot(names)
+	}
+
+	tree := &zipTree{reader: reader, name: name, files: make(map[string]*zip.File), temporary: temporary}
+	for _, f := range reader.File {
+		filePath, found := strings.CutPrefix(f.Name, root)
+		if !found || f.FileInfo().IsDir() || !isSourceFile(f.Name) {
+			continue
+		}
+		tree.paths = append(tree.paths, filePath)
+		tree.files[filePath] = f
+	}
+	SortSourcePaths(tree.paths)
+	return tree, nil
+}
+
+func hasGzipMagic(name string) (bool, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	magic := make([]byte, 2)
+	if _, err := io.ReadFull(f, magic); err != nil {
+		// Zu kurz für ein gzip-Archiv, zip.OpenReader meldet den Fehler
+		return false, nil
+	}
+	return bytes.Equal(magic, []byte{0x1f, 0x8b}), nil
+}
+
+// extractTarGz entpackt die Quelldateien eines tar.gz-Archivs Datei für Datei in ein temporäres Verzeichnis
+func extractTarGz(name, root string) (SourceTree, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(bufio.NewReader(f))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	dir, err := os.MkdirTemp("", "goparser-*")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			os.RemoveAll(dir)
+			return nil, err
+		}
+		entry := strings.TrimPrefix(header.Name, "./")
+		if header.Typeflag != tar.TypeReg || !isSourceFile(entry) || !filepath.IsLocal(filepath.FromSlash(entry)) {
+			continue
+		}
+		if err := writeExtractedFile(filepath.Join(dir, filepath.FromSlash(entry)), tr); err != nil {
+			os.RemoveAll(dir)
+			return nil, err
+		}
+		names = append(names, entry)
+	}
+
+	if root == "" {
+		root = archiveRoot(names)
+	}
+	tree := &dirTree{base: filepath.Join(dir, filepath.FromSlash(root)), removeDir: dir}
+	for _, entry := range names {
+		if filePath, found := strings.CutPrefix(entry, root); found {
+			tree.paths = append(tree.paths, filePath)
+		}
+	}
+	SortSourcePaths(tree.paths)
+	return tree, nil
+}
+
+func writeExtractedFile(name string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeTempArchive schreibt ein Archiv, während es heruntergeladen wird, in eine temporäre Datei,
+// statt es vollständig in den Speicher zu lesen. Bei einem Fehler wird die Datei wieder gelöscht.
+func writeTempArchive(r io.Reader) (string, error) {
+	f, err := os.CreateTemp("", "goparser-*.archive")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// archiveRoot liefert das oberste Verzeichnis inklusive "/", in dem alle Dateien des Archivs liegen,
+// oder "", wenn es keines gibt
+func archiveRoot(names []string) string {
+	root := ""
+	for i, name := range names {
+		dir, _, found := strings.Cut(name, "/")
+		if !found || (i > 0 && dir+"/" != root) {
+			return ""
+		}
+		root = dir + "/"
+	}
+	return root
+}
@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"GoParser/model"
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// readTree liest alle Dateien eines geladenen Baums und schließt ihn. Die Parameter entsprechen den
+// Rückgabewerten von FetchRepository, damit Aufrufe direkt übergeben werden können.
+func readTree(tree SourceTree, commit string, err error) ([]model.SourceFile, string, error) {
+	if err != nil {
+		return nil, "", err
+	}
+	defer tree.Close()
+
+	var files []model.SourceFile
+	for f, err := range SourceFiles(tree) {
+		if err != nil {
+			return nil, "", err
+		}
+		files = append(files, f)
+	}
+	return files, commit, nil
+}
+
+func TestSortSourcePathsKeepsDirectoriesTogether(t *testing.T) {
+	paths := []string{"pkg/util/b.go", "pkg-x/x.go", "pkg/z.go", "main.go", "pkg/util/a.go", "go.mod", "pkg/a.go"}
+	SortSourcePaths(paths)
+
+	want := []string{"go.mod", "main.go", "pkg/a.go", "pkg/z.go", "pkg-x/x.go", "pkg/util/a.go", "pkg/util/b.go"}
+	if !slices.Equal(paths, want) {
+		t.Errorf("got %v, want %v", paths, want)
+	}
+}
+
+func TestOpenLocalTreeReadsFilesLazily(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"go.mod":           "module example.com/local\n",
+		"main.go":          "package main\n",
+		"README.md":        "# local\n",
+		"pkg/list.go":      "package pkg\n",
+		".cache/cached.go": "package cached\n",
+	} {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tree, err := OpenLocalTree(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tree.Close()
+	if want := []string{"go.mod", "main.go", "pkg/list.go"}; !slices.Equal(tree.Paths(), want) {
+		t.Fatalf("got paths %v, want %v", tree.Paths(), want)
+	}
+
+	// Der Inhalt wird erst beim Lesen geladen, eine spätere Änderung ist also sichtbar
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package changed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	files, _, err := readTree(tree, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files[1].Content != "package changed\n" || files[1].Category != model.FileCategoryProduction {
+		t.Errorf("got %+v, want the current content of main.go", files[1])
+	}
+}
+
+func TestOpenArchiveRemovesTemporaryFile(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "archive-*.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, name := range []string{"repo-abc/go.mod", "repo-abc/a/a.go", "repo-abc/README.md"} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(w, "// %s\n", name)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tree, err := openArchive(f.Name(), "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"go.mod", "a/a.go"}; !slices.Equal(tree.Paths(), want) {
+		t.Errorf("got paths %v, want %v", tree.Paths(), want)
+	}
+	if content, err := tree.ReadFile("a/a.go"); err != nil || content != "// repo-abc/a/a.go\n" {
+		t.Errorf("got %q and error %v, want the content of a/a.go", content, err)
+	}
+	if err := tree.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(f.Name()); !os.IsNotExist(err) {
+		t.Errorf("temporary archive still exists after Close: %v", err)
+	}
+}
@@ -0,0 +1,13 @@
+package localtestproject
+
+// Paketweite Analyse: Der Typ ist hier deklariert, seine Methoden liegen in boxMethods.go
+
+// NON-TRIVIAL: Comparable constraint
+type Box[T comparable] struct {
+	value T
+}
+
+// TRIVIAL: any
+type Bag[T any] struct {
+	items []T
+}
@@ -0,0 +1,17 @@
+package localtestproject
+
+// Methoden zu den Typen aus box.go. Die Type Bounds müssen dateiübergreifend aufgelöst werden.
+
+// Method mit non-trivial Type Bound (Receiver ist Box[T comparable])
+func (b Box[T]) Get() T {
+	return b.value
+}
+
+func (b Box[T]) Equals(other T) bool {
+	return b.value == other
+}
+
+// Method mit trivialem Type Bound (Receiver ist Bag[T any])
+func (b *Bag[T]) Add(item T) {
+	b.items = append(b.items, item)
+}